@@ -0,0 +1,103 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// anchoredSchedule fires every n days, or on chosen weekdays of every n-th week,
+// counted from an anchor date. Unlike a cron step such as 1/10 in the day field,
+// the count does not restart at month or week boundaries.
+type anchoredSchedule struct {
+	anchor   time.Time
+	n        int
+	weeks    bool
+	weekdays [7]bool
+}
+
+// EveryDays returns a schedule firing every n days at the wall clock time of anchor,
+// starting with anchor itself.
+func EveryDays(anchor time.Time, n int) (Schedule, error) {
+	if n < 1 {
+		return nil, errors.New(fmt.Sprintf("every %d days: n should be > 0", n))
+	}
+	return &anchoredSchedule{anchor: anchor, n: n}, nil
+}
+
+// EveryWeeks returns a schedule firing on the given weekdays of every n-th week at the
+// wall clock time of anchor. Weeks start on Sunday and are counted from the week
+// containing anchor; no time before anchor fires. Without weekdays the weekday of
+// anchor is used.
+func EveryWeeks(anchor time.Time, n int, weekdays ...time.Weekday) (Schedule, error) {
+	if n < 1 {
+		return nil, errors.New(fmt.Sprintf("every %d weeks: n should be > 0", n))
+	}
+	s := &anchoredSchedule{anchor: anchor, n: n, weeks: true}
+	if len(weekdays) == 0 {
+		weekdays = []time.Weekday{anchor.Weekday()}
+	}
+	for _, wd := range weekdays {
+		if wd < time.Sunday || wd > time.Saturday {
+			return nil, errors.New(fmt.Sprintf("weekday %d should be in [0,6]", wd))
+		}
+		s.weekdays[wd] = true
+	}
+	return s, nil
+}
+
+func (s *anchoredSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.anchor.Location())
+	clock := clockOf(s.anchor)
+	day := midnight(t)
+	if day.Before(midnight(s.anchor)) {
+		day = midnight(s.anchor)
+	}
+	if !s.weeks {
+		//直接跳到下一个间隔的日期
+		if rem := civilDays(s.anchor, day) % s.n; rem > 0 {
+			day = day.AddDate(0, 0, s.n-rem)
+		}
+		for {
+			if next := addClock(day, clock); next.After(t) {
+				return next
+			}
+			day = day.AddDate(0, 0, s.n)
+		}
+	}
+	weekStart := midnight(s.anchor).AddDate(0, 0, -int(s.anchor.Weekday()))
+	//最多需要看完一个完整的间隔周期
+	for i := 0; i <= 7*(s.n+1); i++ {
+		if civilDays(weekStart, day)/7%s.n == 0 && s.weekdays[day.Weekday()] {
+			if next := addClock(day, clock); next.After(t) && !next.Before(s.anchor) {
+				return next
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+func (s *anchoredSchedule) rrule() (time.Time, string, []time.Time, bool) {
+	if !s.weeks {
+		return s.anchor, fmt.Sprintf("FREQ=DAILY;INTERVAL=%d", s.n), nil, true
+	}
+	var days []string
+	for wd, ok := range s.weekdays {
+		if ok {
+			days = append(days, strings.ToUpper(time.Weekday(wd).String()[:2]))
+		}
+	}
+	//DTSTART本身总算一次，anchor不在选中的星期几时从第一次触发开始
+	first := s.Next(s.anchor.Add(-time.Nanosecond))
+	return first, fmt.Sprintf("FREQ=WEEKLY;INTERVAL=%d;BYDAY=%s;WKST=SU", s.n, strings.Join(days, ",")), nil, true
+}
+
+// civilDays returns the number of calendar days from the date of a to the date of b,
+// ignoring DST changes in between
+func civilDays(a, b time.Time) int {
+	y0, m0, d0 := a.Date()
+	y1, m1, d1 := b.Date()
+	return int(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC).Sub(time.Date(y0, m0, d0, 0, 0, 0, 0, time.UTC)) / (24 * time.Hour))
+}
@@ -0,0 +1,47 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEveryWeeks(t *testing.T) {
+	anchor := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	s, err := EveryWeeks(anchor, 2, time.Monday, time.Thursday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 8, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 19, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 22, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC),
+	}
+	now := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	for _, w := range want {
+		if now = s.Next(now); !now.Equal(w) {
+			t.Fatalf("next = %v, want %v", now, w)
+		}
+	}
+}
+
+func TestEveryDaysAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	s, err := EveryDays(time.Date(2026, 2, 26, 9, 30, 0, 0, loc), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2026-03-08 开始夏令时，仍然在当地时间09:30触发
+	got := s.Next(time.Date(2026, 2, 26, 9, 30, 0, 0, loc))
+	if want := time.Date(2026, 3, 8, 9, 30, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("next = %v, want %v", got, want)
+	}
+	got = s.Next(time.Date(2026, 4, 30, 0, 0, 0, 0, loc))
+	if want := time.Date(2026, 5, 7, 9, 30, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("next = %v, want %v", got, want)
+	}
+}
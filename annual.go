@@ -0,0 +1,119 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AnnualDate computes a date of the given year, such as Easter.
+type AnnualDate func(year int) (time.Month, int)
+
+// Easter returns the date of Western Easter Sunday in the Gregorian calendar.
+func Easter(year int) (time.Month, int) {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	n := h + l - 7*m + 114
+	return time.Month(n / 31), n%31 + 1
+}
+
+// OrthodoxEaster returns the date of Orthodox Easter Sunday, converted from the Julian to the
+// Gregorian calendar.
+func OrthodoxEaster(year int) (time.Month, int) {
+	a, b, c := year%4, year%7, year%19
+	d := (19*c + 15) % 30
+	e := (2*a + 4*b - d + 34) % 7
+	n := d + e + 114
+	//儒略历与格里高利历相差的天数
+	t := time.Date(year, time.Month(n/31), n%31+1+year/100-year/400-2, 0, 0, 0, 0, time.UTC)
+	return t.Month(), t.Day()
+}
+
+// GoodFriday returns the date of Good Friday, two days before Western Easter.
+func GoodFriday(year int) (time.Month, int) {
+	m, d := Easter(year)
+	t := time.Date(year, m, d-2, 0, 0, 0, 0, time.UTC)
+	return t.Month(), t.Day()
+}
+
+// Thanksgiving returns the date of US Thanksgiving, the fourth Thursday of November.
+func Thanksgiving(year int) (time.Month, int) {
+	return NthWeekday(time.November, 4, time.Thursday)(year)
+}
+
+// NthWeekday returns the n-th weekday of month, counted from the end of the month if n is negative.
+func NthWeekday(month time.Month, n int, weekday time.Weekday) AnnualDate {
+	return func(year int) (time.Month, int) {
+		if n < 0 {
+			t := getMonthLatestWeek(year, int(month), int(weekday)).AddDate(0, 0, 7*(n+1))
+			if t.Month() != month {
+				return 0, 0
+			}
+			return t.Month(), t.Day()
+		}
+		t := getMonthWeekByWeekNumDay(year, int(month), uint(n), uint(weekday))
+		if t == nil {
+			return 0, 0
+		}
+		return t.Month(), t.Day()
+	}
+}
+
+// WeekdayAfter returns the first weekday strictly after date, e.g. WeekdayAfter(Easter, time.Monday).
+func WeekdayAfter(date AnnualDate, weekday time.Weekday) AnnualDate {
+	return func(year int) (time.Month, int) {
+		m, d := date(year)
+		if m == 0 {
+			return 0, 0
+		}
+		t := time.Date(year, m, d, 0, 0, 0, 0, time.UTC)
+		t = t.AddDate(0, 0, (int(weekday)-int(t.Weekday())+6)%7+1)
+		return t.Month(), t.Day()
+	}
+}
+
+// annualSchedule fires once a year on a computed date
+type annualSchedule struct {
+	date   AnnualDate
+	offset int
+	clock  time.Duration
+}
+
+// Annual returns a schedule firing at clock (HH:MM or HH:MM:SS) offsetDays days after the
+// date computed for every year. A date returning month 0 is skipped that year.
+//
+//	Annual(Easter, -2, "09:00")        Good Friday
+//	Annual(OrthodoxEaster, 1, "09:00") Orthodox Easter Monday
+//	Annual(Thanksgiving, 0, "08:00")
+func Annual(date AnnualDate, offsetDays int, clock string) (Schedule, error) {
+	if date == nil {
+		return nil, errors.New("annual date should not be nil")
+	}
+	c, err := parseClock(clock)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("annual %s", err))
+	}
+	return &annualSchedule{date: date, offset: offsetDays, clock: c}, nil
+}
+
+func (s *annualSchedule) Next(t time.Time) time.Time {
+	//offset可能跨年，从前一年开始算
+	for year := t.Year() - 1; year <= t.Year()+maxYears; year++ {
+		m, d := s.date(year)
+		if m == 0 {
+			continue
+		}
+		next := addClock(time.Date(year, m, d+s.offset, 0, 0, 0, 0, t.Location()), s.clock)
+		if next.After(t) {
+			return next
+		}
+	}
+	return time.Time{}
+}
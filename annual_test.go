@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnnualDates(t *testing.T) {
+	cases := []struct {
+		name  string
+		date  AnnualDate
+		year  int
+		month time.Month
+		day   int
+	}{
+		{"easter", Easter, 2025, time.April, 20},
+		{"easter", Easter, 2026, time.April, 5},
+		{"easter", Easter, 2027, time.March, 28},
+		{"orthodox easter", OrthodoxEaster, 2025, time.April, 20},
+		{"orthodox easter", OrthodoxEaster, 2026, time.April, 12},
+		{"orthodox easter", OrthodoxEaster, 2027, time.May, 2},
+		{"good friday", GoodFriday, 2026, time.April, 3},
+		{"thanksgiving", Thanksgiving, 2026, time.November, 26},
+		{"easter monday", WeekdayAfter(Easter, time.Monday), 2026, time.April, 6},
+		{"last monday of may", NthWeekday(time.May, -1, time.Monday), 2026, time.May, 25},
+	}
+	for _, c := range cases {
+		if m, d := c.date(c.year); m != c.month || d != c.day {
+			t.Errorf("%s %d = %v %d, want %v %d", c.name, c.year, m, d, c.month, c.day)
+		}
+	}
+}
+
+func TestAnnual(t *testing.T) {
+	s, err := Annual(Easter, 1, "09:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Next(time.Date(2026, 4, 6, 9, 30, 0, 0, time.UTC)), time.Date(2027, 3, 29, 9, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("next = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,247 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Calendar excludes periods of time from a job's schedule. Fire times
+// produced by the trigger that fall into an excluded period are skipped and
+// the next non-excluded fire time is used instead.
+type Calendar interface {
+	Excluded(t time.Time) bool
+}
+
+// CalendarFunc adapts an ordinary function to the Calendar interface.
+type CalendarFunc func(t time.Time) bool
+
+func (f CalendarFunc) Excluded(t time.Time) bool {
+	return f(t)
+}
+
+// calendarSkipper is implemented by calendars that know where an excluded
+// period ends, so that a job does not have to probe it second by second.
+type calendarSkipper interface {
+	// includedAfter returns the first instant after the excluded period that contains t
+	includedAfter(t time.Time) time.Time
+}
+
+// MonthDay is a day of the year, e.g. {time.December, 25}.
+type MonthDay struct {
+	Month time.Month
+	Day   int
+}
+
+type dateCalendar struct {
+	dates map[[3]int]struct{}
+}
+
+// DatesCalendar excludes the whole days of the given dates.
+func DatesCalendar(dates ...time.Time) Calendar {
+	c := &dateCalendar{dates: make(map[[3]int]struct{}, len(dates))}
+	for _, d := range dates {
+		y, m, day := d.Date()
+		c.dates[[3]int{y, int(m), day}] = struct{}{}
+	}
+	return c
+}
+func (c *dateCalendar) Excluded(t time.Time) bool {
+	y, m, d := t.Date()
+	_, ok := c.dates[[3]int{y, int(m), d}]
+	return ok
+}
+func (c *dateCalendar) includedAfter(t time.Time) time.Time {
+	return nextMidnight(t)
+}
+
+type rangeCalendar struct {
+	start time.Time
+	end   time.Time
+}
+
+// RangeCalendar excludes every instant in [start, end).
+func RangeCalendar(start, end time.Time) Calendar {
+	return &rangeCalendar{start: start, end: end}
+}
+func (c *rangeCalendar) Excluded(t time.Time) bool {
+	return !t.Before(c.start) && t.Before(c.end)
+}
+func (c *rangeCalendar) includedAfter(t time.Time) time.Time {
+	return c.end
+}
+
+type annualCalendar struct {
+	days map[MonthDay]struct{}
+}
+
+// AnnualCalendar excludes the given days every year, e.g. public holidays with a fixed date.
+func AnnualCalendar(days ...MonthDay) Calendar {
+	c := &annualCalendar{days: make(map[MonthDay]struct{}, len(days))}
+	for _, d := range days {
+		c.days[d] = struct{}{}
+	}
+	return c
+}
+func (c *annualCalendar) Excluded(t time.Time) bool {
+	_, m, d := t.Date()
+	_, ok := c.days[MonthDay{Month: m, Day: d}]
+	return ok
+}
+func (c *annualCalendar) includedAfter(t time.Time) time.Time {
+	return nextMidnight(t)
+}
+
+type dailyCalendar struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// DailyCalendar excludes the time window [start, end) of every day. start and end are
+// written as HH:MM or HH:MM:SS; a window whose end is before its start spans midnight.
+func DailyCalendar(start, end string) (Calendar, error) {
+	s, err := parseClock(start)
+	if err != nil {
+		return nil, err
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return nil, err
+	}
+	if s == e {
+		return nil, errors.New(fmt.Sprintf("daily calendar %s-%s is empty", start, end))
+	}
+	return &dailyCalendar{start: s, end: e}, nil
+}
+func (c *dailyCalendar) Excluded(t time.Time) bool {
+	d := clockOf(t)
+	if c.start < c.end {
+		return d >= c.start && d < c.end
+	}
+	return d >= c.start || d < c.end
+}
+func (c *dailyCalendar) includedAfter(t time.Time) time.Time {
+	day := midnight(t)
+	if c.start > c.end && clockOf(t) >= c.start {
+		day = nextMidnight(t)
+	}
+	return addClock(day, c.end)
+}
+
+type cronCalendar struct {
+	t *trigger
+}
+
+// CronCalendar excludes every second matched by the cron expression.
+func CronCalendar(cronExpression string) (Calendar, error) {
+	t, err := newTrigger(cronExpression)
+	if err != nil {
+		return nil, err
+	}
+	return &cronCalendar{t: t}, nil
+}
+func (c *cronCalendar) Excluded(t time.Time) bool {
+	sec := t.Truncate(time.Second)
+	return c.t.next(sec).Equal(sec)
+}
+
+// includedAfter returns the first second after t the expression does not match. Whole minutes,
+// hours and days are skipped at once when the fields below them match every value.
+func (c *cronCalendar) includedAfter(t time.Time) time.Time {
+	next := t.In(time.Local).Truncate(time.Second).Add(time.Second)
+	limit := t.AddDate(maxYears, 0, 0)
+	for next.Before(limit) && c.Excluded(next) {
+		//按绝对时间加到下一分、下一小时，夏令时重复的那一小时也不会往回走
+		toMinute := time.Duration(60-next.Second()) * time.Second
+		switch {
+		case !c.t.sec.all():
+			next = next.Add(time.Second)
+		case !c.t.min.all():
+			next = next.Add(toMinute)
+		case !c.t.hour.all():
+			next = next.Add(toMinute + time.Duration(59-next.Minute())*time.Minute)
+		default:
+			next = nextMidnight(next)
+		}
+	}
+	return next
+}
+
+// calendars 多个日历，任意一个排除即排除
+type calendars []Calendar
+
+func (cs calendars) Excluded(t time.Time) bool {
+	for _, c := range cs {
+		if c.Excluded(t) {
+			return true
+		}
+	}
+	return false
+}
+func (cs calendars) includedAfter(t time.Time) time.Time {
+	next := t.Add(time.Nanosecond)
+	for _, c := range cs {
+		if !c.Excluded(t) {
+			continue
+		}
+		if s, ok := c.(calendarSkipper); ok {
+			if after := s.includedAfter(t); after.After(next) {
+				next = after
+			}
+		}
+	}
+	return next
+}
+
+// parseClock parses HH:MM or HH:MM:SS into the offset from midnight
+func parseClock(s string) (time.Duration, error) {
+	arr := strings.Split(s, ":")
+	if len(arr) != 2 && len(arr) != 3 {
+		return 0, errors.New(fmt.Sprintf("clock %s should be HH:MM or HH:MM:SS", s))
+	}
+	limits := []uint64{23, 59, 59}
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, str := range arr {
+		v, err := strconv.ParseUint(str, 10, 8)
+		if err != nil || v > limits[i] {
+			return 0, errors.New(fmt.Sprintf("clock %s should be HH:MM or HH:MM:SS", s))
+		}
+		d += time.Duration(v) * units[i]
+	}
+	return d, nil
+}
+
+// addClock returns the wall clock time d after midnight of day, so that DST changes do not shift it
+func addClock(day time.Time, d time.Duration) time.Time {
+	y, m, dd := day.Date()
+	return time.Date(y, m, dd, int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second), int(d%time.Second), day.Location())
+}
+
+// clockOf returns the wall clock time of t as the offset from midnight
+func clockOf(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+}
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return dayStart(y, m, d, t.Location())
+}
+func nextMidnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return dayStart(y, m, d+1, t.Location())
+}
+
+// dayStart returns the first instant of the date, which is not 00:00 when DST starts at midnight
+func dayStart(year int, month time.Month, day int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if _, _, d := t.Date(); d != time.Date(year, month, day, 12, 0, 0, 0, loc).Day() {
+		//0点被跳过，time.Date落在前一天，加上跳过的时长
+		_, before := t.Zone()
+		_, after := t.Add(24 * time.Hour).Zone()
+		t = t.Add(time.Duration(after-before) * time.Second)
+	}
+	return t
+}
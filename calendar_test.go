@@ -0,0 +1,74 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobCalendars(t *testing.T) {
+	daily, err := DailyCalendar("22:00", "09:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	holiday := time.Date(2026, 3, 3, 0, 0, 0, 0, time.Local)
+	j := newJob(MustParse("0 0 * * * ?"), plainJob(func() {}), WithCalendars(daily, DatesCalendar(holiday), AnnualCalendar(MonthDay{time.March, 5})))
+	cases := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 3, 2, 21, 30, 0, 0, time.Local), time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local)},
+		{time.Date(2026, 3, 4, 10, 30, 0, 0, time.Local), time.Date(2026, 3, 4, 11, 0, 0, 0, time.Local)},
+		{time.Date(2026, 3, 4, 22, 0, 0, 0, time.Local), time.Date(2026, 3, 6, 9, 0, 0, 0, time.Local)},
+	}
+	for _, c := range cases {
+		if got := j.nextIncluded(c.now); !got.Equal(c.want) {
+			t.Errorf("next(%v) = %v, want %v", c.now, got, c.want)
+		}
+	}
+}
+
+func TestCronCalendar(t *testing.T) {
+	c, err := CronCalendar("* * 12 ? * SAT,SUN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Excluded(time.Date(2026, 3, 7, 12, 30, 0, 0, time.Local)) {
+		t.Error("saturday noon should be excluded")
+	}
+	if c.Excluded(time.Date(2026, 3, 9, 12, 30, 0, 0, time.Local)) {
+		t.Error("monday noon should not be excluded")
+	}
+}
+
+func TestCronCalendarSkipsWeekend(t *testing.T) {
+	weekend, err := CronCalendar("* * * ? * SAT,SUN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//每秒执行的任务跳过整个周末
+	j := newJob(MustParse("* * * * * ?"), plainJob(func() {}), WithCalendars(weekend))
+	friday := time.Date(2026, 3, 6, 23, 59, 59, 0, time.Local)
+	monday := time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local)
+	if got := j.nextIncluded(friday); !got.Equal(monday) {
+		t.Errorf("next(%v) = %v, want %v", friday, got, monday)
+	}
+	nights, err := CronCalendar("* * 0-5 * * ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j = newJob(MustParse("* * * * * ?"), plainJob(func() {}), WithCalendars(nights, weekend))
+	if want := monday.Add(6 * time.Hour); !j.nextIncluded(friday).Equal(want) {
+		t.Errorf("next(%v) = %v, want %v", friday, j.nextIncluded(friday), want)
+	}
+
+	s := New()
+	id := s.AddSchedule(MustParse("* * * * * ?"), func() {}, WithCalendars(weekend))
+	var b strings.Builder
+	if err := s.ExportJobICS(&b, id, friday.Add(-time.Second), monday.Add(3*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(b.String(), "BEGIN:VEVENT"); n != 5 {
+		t.Errorf("exported %d events, want 2 on friday and 3 on monday", n)
+	}
+}
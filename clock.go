@@ -0,0 +1,224 @@
+package cron
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the source of time of the Scheduler.
+type Clock interface {
+	Now() time.Time
+	// NewTimer returns a timer sending the time on its channel after d.
+	NewTimer(d time.Duration) Timer
+	// AfterFunc calls f after d.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock.
+type Timer interface {
+	// C returns the channel the time is sent on, nil for AfterFunc timers.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it returns false if it has already fired or been stopped.
+	Stop() bool
+}
+
+// WithClock makes the scheduler use clock instead of the system clock, e.g. a FakeClock in tests.
+func WithClock(clock Clock) Option {
+	return func(c *Scheduler) {
+		c.clock = clock
+	}
+}
+
+// realClock 系统时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.t.C
+}
+func (t realTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// FakeClock is a Clock whose time only moves when Advance or Set is called, for tests.
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock returns a FakeClock at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	c        chan time.Time
+	f        func()
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.add(d, make(chan time.Time, 1), nil)
+}
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.add(d, nil, f)
+}
+
+func (c *FakeClock) add(d time.Duration, ch chan time.Time, f func()) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), c: ch, f: f}
+	//和time.NewTimer一样，d<=0时立即触发；调用方可能持有锁，f在新的goroutine里执行
+	if d <= 0 {
+		if f != nil {
+			go f()
+		} else {
+			ch <- c.now
+		}
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// remove removes t from the pending timers, called with c.mu held
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, p := range c.timers {
+		if p == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by d, firing the timers that are due in deadline order.
+// AfterFunc functions are called synchronously before Advance returns, except those created
+// with d <= 0 which run in their own goroutine.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+	c.Set(end)
+}
+
+// Set moves the clock to t, firing the timers that are due in deadline order. The clock never
+// moves backwards.
+func (c *FakeClock) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool {
+			return c.timers[i].deadline.Before(c.timers[j].deadline)
+		})
+		if len(c.timers) == 0 || c.timers[0].deadline.After(t) {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mu.Unlock()
+			return
+		}
+		timer := c.timers[0]
+		c.timers = c.timers[1:]
+		if timer.deadline.After(c.now) {
+			c.now = timer.deadline
+		}
+		now := c.now
+		c.cond.Broadcast()
+		c.mu.Unlock()
+		//在锁外触发，回调可能再调用时钟
+		if timer.f != nil {
+			timer.f()
+		} else {
+			select {
+			case timer.c <- now:
+			default:
+			}
+		}
+	}
+}
+
+// BlockUntil blocks until at least n timers are pending, e.g. until the scheduler is waiting
+// for its next fire time after a change.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// deadlineContext is cancelled by a Clock timer when the timeout passes, reporting
+// context.DeadlineExceeded like context.WithTimeout. Unlike it, cancelling does not stop
+// the timer, so expired still tells when the timeout has passed; stop releases the timer.
+type deadlineContext struct {
+	context.Context
+	deadline time.Time
+	timer    Timer
+	expired  chan struct{}
+	exceeded int32
+}
+
+// withClockTimeout is context.WithTimeout measured by clock
+func withClockTimeout(parent context.Context, clock Clock, timeout time.Duration) (*deadlineContext, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	d := &deadlineContext{Context: ctx, deadline: clock.Now().Add(timeout), expired: make(chan struct{})}
+	d.timer = clock.AfterFunc(timeout, func() {
+		if ctx.Err() == nil {
+			atomic.StoreInt32(&d.exceeded, 1)
+		}
+		close(d.expired)
+		cancel()
+	})
+	return d, cancel
+}
+
+func (d *deadlineContext) stop() {
+	d.timer.Stop()
+}
+
+func (d *deadlineContext) Deadline() (time.Time, bool) {
+	return d.deadline, true
+}
+func (d *deadlineContext) Err() error {
+	if atomic.LoadInt32(&d.exceeded) == 1 {
+		return context.DeadlineExceeded
+	}
+	return d.Context.Err()
+}
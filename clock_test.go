@@ -0,0 +1,155 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testStart is where the fake clocks of the scheduler tests start, a Monday
+var testStart = time.Date(2026, 7, 6, 8, 0, 0, 0, time.Local)
+
+// recorder collects the events of a scheduler so that tests can wait for them
+type recorder struct {
+	ch   chan Event
+	seen []Event
+}
+
+func newRecorder() *recorder {
+	return &recorder{ch: make(chan Event, 1000)}
+}
+
+func (r *recorder) handle(e Event) {
+	r.ch <- e
+}
+
+// wait returns the first event of type typ not returned before, failing after a second
+func (r *recorder) wait(t *testing.T, typ EventType) Event {
+	t.Helper()
+	for i, e := range r.seen {
+		if e.Type == typ {
+			r.seen = append(r.seen[:i], r.seen[i+1:]...)
+			return e
+		}
+	}
+	for {
+		select {
+		case e := <-r.ch:
+			if e.Type == typ {
+				return e
+			}
+			r.seen = append(r.seen, e)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for a %s event", typ)
+		}
+	}
+}
+
+// count returns how many events of type typ have been emitted and not returned by wait
+func (r *recorder) count(typ EventType) int {
+	for drained := false; !drained; {
+		select {
+		case e := <-r.ch:
+			r.seen = append(r.seen, e)
+		default:
+			drained = true
+		}
+	}
+	n := 0
+	for _, e := range r.seen {
+		if e.Type == typ {
+			n++
+		}
+	}
+	return n
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2026, 7, 6, 8, 0, 0, 0, time.Local)
+	clock := NewFakeClock(start)
+	var order []int
+	late := clock.NewTimer(3 * time.Second)
+	clock.AfterFunc(2*time.Second, func() {
+		order = append(order, 2)
+		//回调里看到的是自己的触发时间
+		if got := clock.Now(); !got.Equal(start.Add(2 * time.Second)) {
+			t.Errorf("now in AfterFunc = %v", got)
+		}
+	})
+	clock.AfterFunc(time.Second, func() { order = append(order, 1) })
+	stopped := clock.AfterFunc(time.Second, func() { order = append(order, 0) })
+	if !stopped.Stop() || stopped.Stop() {
+		t.Error("Stop should report true only once")
+	}
+	clock.BlockUntil(3)
+
+	clock.Advance(2 * time.Second)
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Errorf("AfterFunc order = %v, want [1 2]", order)
+	}
+	select {
+	case <-late.C():
+		t.Error("timer fired early")
+	default:
+	}
+	clock.Advance(5 * time.Second)
+	select {
+	case now := <-late.C():
+		if !now.Equal(start.Add(3 * time.Second)) {
+			t.Errorf("timer fired at %v", now)
+		}
+	default:
+		t.Error("timer did not fire")
+	}
+	if got := clock.Now(); !got.Equal(start.Add(7 * time.Second)) {
+		t.Errorf("now = %v", got)
+	}
+	clock.Set(start)
+	if got := clock.Now(); !got.Equal(start.Add(7 * time.Second)) {
+		t.Errorf("clock moved backwards to %v", got)
+	}
+}
+
+func TestClockTimeout(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 7, 6, 8, 0, 0, 0, time.Local))
+	ctx, cancel := withClockTimeout(context.Background(), clock, time.Minute)
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(clock.Now().Add(time.Minute)) {
+		t.Errorf("deadline = %v, %v", deadline, ok)
+	}
+	clock.Advance(59 * time.Second)
+	if ctx.Err() != nil {
+		t.Fatalf("expired early: %v", ctx.Err())
+	}
+	clock.Advance(time.Second)
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("err = %v, want deadline exceeded", ctx.Err())
+	}
+
+	//取消后expired仍在超时时关闭
+	ctx, cancel = withClockTimeout(context.Background(), clock, time.Minute)
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("err = %v, want canceled", ctx.Err())
+	}
+	clock.Advance(time.Minute)
+	select {
+	case <-ctx.expired:
+	default:
+		t.Error("expired not closed after the timeout")
+	}
+	if ctx.Err() != context.Canceled {
+		t.Errorf("err = %v after the timeout, want canceled", ctx.Err())
+	}
+
+	ctx, cancel = withClockTimeout(context.Background(), clock, time.Minute)
+	ctx.stop()
+	cancel()
+	clock.Advance(time.Minute)
+	select {
+	case <-ctx.expired:
+		t.Error("expired closed after stop")
+	default:
+	}
+}
@@ -0,0 +1,400 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect is a cron expression format Convert reads or writes.
+type Dialect int
+
+const (
+	// Native is the 6-field format of this package: sec min hour day mon week.
+	Native Dialect = iota
+	// Unix is the 5-field crontab format: min hour day mon week, week 0-7 with 0 and 7 Sunday.
+	// Macros such as @daily are accepted as input. A day and a week both restricted means
+	// either matches in Unix, which cannot be converted.
+	Unix
+	// Quartz is the 7-field Quartz format: sec min hour day mon week year, week 1-7 with
+	// 1 Sunday. The year is optional as input.
+	Quartz
+	// Systemd is the OnCalendar= format of systemd timers, e.g. "Mon..Fri *-*-* 09:00:00".
+	// As input it is read with ParseOnCalendar, a year or a timezone is dropped.
+	Systemd
+	// Kubernetes is the schedule of a Kubernetes CronJob, the same 5 fields as Unix.
+	Kubernetes
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case Native:
+		return "native"
+	case Unix:
+		return "unix"
+	case Quartz:
+		return "quartz"
+	case Systemd:
+		return "systemd"
+	case Kubernetes:
+		return "kubernetes"
+	}
+	return "dialect(" + strconv.Itoa(int(d)) + ")"
+}
+
+// unixMacros 5个字段的预定义表达式
+var unixMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Convert translates cronExpression from one dialect to another. H and ~ are resolved to
+// their values first, so opts such as WithHashKey should match how the expression is used.
+// losses lists what the result does not keep, e.g. seconds dropped for Unix or the year
+// of a Quartz expression. Constructs the target cannot express at all, e.g. L or W for Unix
+// or BD for anything but Native, are an error.
+//
+//	Convert("0 15 10 ? * MON-FRI", Native, Systemd) // "Mon..Fri *-*-* 10:15:00"
+//	Convert("*/5 9-17 * * 1-5", Unix, Quartz)       // "0 0/5 9-17 ? * 2-6 *"
+func Convert(cronExpression string, from, to Dialect, opts ...ParseOption) (result string, losses []string, err error) {
+	native := cronExpression
+	switch from {
+	case Native:
+	case Unix, Kubernetes:
+		native, err = unixToNative(cronExpression)
+	case Quartz:
+		native, losses, err = quartzToNative(cronExpression)
+	case Systemd:
+		native, losses, err = systemdToNative(cronExpression)
+	default:
+		err = errors.New(fmt.Sprintf("converting from %s is not supported", from))
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	t, err := newTrigger(native, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	if t.holidays != nil && t.day.calculate != nil {
+		losses = append(losses, "holidays are not part of the expression")
+	}
+	if to != Native && t.ms != nil {
+		losses = append(losses, fmt.Sprintf("ms %s dropped", t.ms.expr))
+	}
+	var more []string
+	switch to {
+	case Native:
+		exprs := []string{t.sec.expr, t.min.expr, t.hour.expr, t.day.expr, t.mon.expr, t.week.expr}
+		if t.ms != nil {
+			exprs = append([]string{t.ms.expr}, exprs...)
+		}
+		result = strings.Join(exprs, " ")
+	case Unix, Kubernetes:
+		result, more, err = t.unix(to)
+	case Quartz:
+		result, err = t.quartz()
+	case Systemd:
+		result, err = t.systemd()
+	default:
+		err = errors.New(fmt.Sprintf("converting to %s is not supported", to))
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return result, append(losses, more...), nil
+}
+
+func unixToNative(expr string) (string, error) {
+	if macro, ok := unixMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	arr := strings.Split(strings.ToUpper(expr), " ")
+	if len(arr) != 5 {
+		return "", errors.New("cronExpression's fields count is not 5")
+	}
+	day, week := arr[2], arr[4]
+	if isAnyField(week) {
+		week = "?"
+	} else if !isAnyField(day) {
+		return "", errors.New(fmt.Sprintf("day %s or week %s cannot be converted, only one of them can be restricted", day, week))
+	} else {
+		day = "?"
+		//7也是星期天
+		items := strings.Split(week, ",")
+		for i, item := range items {
+			//表达式不支持列表里有范围，展开为列表
+			if start, err := strconv.Atoi(strings.TrimSuffix(item, "-7")); err == nil && strings.HasSuffix(item, "-7") {
+				var days []string
+				for d := start; d <= 7; d++ {
+					days = append(days, strconv.Itoa(d))
+				}
+				items[i] = strings.Join(days, ",")
+			}
+		}
+		week = mapWeekItems(strings.Join(items, ","), func(n int) int { return n % 7 })
+	}
+	return strings.Join([]string{"0", arr[0], arr[1], day, arr[3], week}, " "), nil
+}
+
+func quartzToNative(expr string) (string, []string, error) {
+	arr := strings.Split(strings.ToUpper(expr), " ")
+	if len(arr) != 6 && len(arr) != 7 {
+		return "", nil, errors.New("cronExpression's fields count is not 6 or 7")
+	}
+	var losses []string
+	if len(arr) == 7 {
+		if !isAnyField(arr[6]) {
+			losses = append(losses, fmt.Sprintf("year %s dropped", arr[6]))
+		}
+		arr = arr[:6]
+	}
+	//Quartz的星期1-7对应SUN-SAT，单独的L是星期六
+	if arr[5] == "L" {
+		arr[5] = "7"
+	}
+	arr[5] = mapWeekItems(arr[5], func(n int) int { return n - 1 })
+	return strings.Join(arr, " "), losses, nil
+}
+
+func systemdToNative(spec string) (string, []string, error) {
+	sched, err := ParseOnCalendar(spec)
+	if err != nil {
+		return "", nil, err
+	}
+	s := sched.(*systemdSchedule)
+	var losses []string
+	if years := setExpr(s.years, systemdMinYear, systemdMaxYear); years != "*" {
+		losses = append(losses, fmt.Sprintf("year %s dropped", years))
+	}
+	if s.loc != time.Local {
+		losses = append(losses, fmt.Sprintf("timezone %s dropped", s.loc))
+	}
+	day, week := "?", "?"
+	weekdays := setExpr(s.weekdays, 0, 6)
+	switch {
+	case s.lastDays != nil:
+		lastDays := setExpr(s.lastDays, 1, 31)
+		//~01是最后一天，最后7天里的星期几是最后一个星期几
+		if lastDays == "1" && weekdays == "*" {
+			day = "L"
+		} else if lastDays == "1-7" && len(weekdays) == 1 {
+			week = weekdays + "L"
+		} else {
+			return "", nil, errors.New(fmt.Sprintf("calendar event %s cannot be converted, ~%s is only supported as ~01 or with one weekday as ~07/1", spec, lastDays))
+		}
+	case weekdays == "*":
+		day = setExpr(s.days, 1, 31)
+	default:
+		week = weekdays
+		//第n个星期几在第(n-1)*7+1到n*7天之间
+		if days := setExpr(s.days, 1, 31); days != "*" {
+			var n int
+			for n = 1; n <= 4 && days != fmt.Sprintf("%d-%d", (n-1)*7+1, n*7); n++ {
+			}
+			if n > 4 || len(weekdays) != 1 {
+				return "", nil, errors.New(fmt.Sprintf("calendar event %s cannot be converted, only one of the day and the weekday can be restricted", spec))
+			}
+			week = fmt.Sprintf("%s#%d", weekdays, n)
+		}
+	}
+	return strings.Join([]string{
+		setExpr(s.secs, 0, 59),
+		setExpr(s.mins, 0, 59),
+		setExpr(s.hours, 0, 23),
+		day,
+		setExpr(s.months, 1, 12),
+		week,
+	}, " "), losses, nil
+}
+
+// setExpr writes the values in [min,max] of a set as *, a value, a range a-b, a step a/n
+// running to max or a list
+func setExpr(set []bool, min, max int) string {
+	var values []int
+	for v := min; v <= max; v++ {
+		if set[v] {
+			values = append(values, v)
+		}
+	}
+	n := len(values)
+	switch {
+	case n == max-min+1:
+		return "*"
+	case n == 1:
+		return strconv.Itoa(values[0])
+	case values[n-1]-values[0] == n-1:
+		return fmt.Sprintf("%d-%d", values[0], values[n-1])
+	}
+	//两个值写成列表
+	step := values[1] - values[0]
+	isStep := n > 2 && values[n-1]+step > max
+	for i := 2; i < n && isStep; i++ {
+		isStep = values[i]-values[i-1] == step
+	}
+	if isStep {
+		return fmt.Sprintf("%d/%d", values[0], step)
+	}
+	items := make([]string, n)
+	for i, v := range values {
+		items[i] = strconv.Itoa(v)
+	}
+	return strings.Join(items, ",")
+}
+
+func (t *trigger) unix(to Dialect) (string, []string, error) {
+	var losses []string
+	if !(t.sec.isRange && t.sec.start == 0 && t.sec.end == 0) {
+		losses = append(losses, fmt.Sprintf("sec %s dropped, fires at second 0", t.sec.expr))
+	}
+	if t.day.calculate != nil {
+		return "", nil, errors.New(fmt.Sprintf("day %s cannot be represented in %s", t.day.expr, to))
+	}
+	if t.week.calculate != nil {
+		return "", nil, errors.New(fmt.Sprintf("week %s cannot be represented in %s", t.week.expr, to))
+	}
+	itoa := func(v uint) string { return strconv.Itoa(int(v)) }
+	return strings.Join([]string{
+		renderField(t.min, itoa, "-", true),
+		renderField(t.hour, itoa, "-", true),
+		renderField(t.day, itoa, "-", true),
+		renderField(t.mon, itoa, "-", true),
+		renderField(t.week, itoa, "-", true),
+	}, " "), losses, nil
+}
+
+func (t *trigger) quartz() (string, error) {
+	itoa := func(v uint) string { return strconv.Itoa(int(v)) }
+	day, week := "?", "?"
+	if isAnyField(t.week.expr) {
+		day = renderField(t.day, itoa, "-", true)
+		if t.day.calculate != nil {
+			if strings.HasSuffix(t.day.expr, "BD") {
+				return "", errors.New(fmt.Sprintf("day %s cannot be represented in %s", t.day.expr, Quartz))
+			}
+			day = t.day.expr
+		}
+	} else if t.week.calculate != nil {
+		week = t.week.expr
+		if week == "L" {
+			week = "6L"
+		}
+		week = mapWeekItems(week, func(n int) int { return n + 1 })
+	} else {
+		week = renderField(t.week, func(v uint) string { return strconv.Itoa(int(v) + 1) }, "-", true)
+	}
+	return strings.Join([]string{
+		renderField(t.sec, itoa, "-", true),
+		renderField(t.min, itoa, "-", true),
+		renderField(t.hour, itoa, "-", true),
+		day,
+		renderField(t.mon, itoa, "-", true),
+		week,
+		"*",
+	}, " "), nil
+}
+
+func (t *trigger) systemd() (string, error) {
+	pad := func(v uint) string { return fmt.Sprintf("%02d", v) }
+	weekName := func(v uint) string { return systemdWeekdays[v] }
+	week := ""
+	date := "*-" + renderField(t.mon, pad, "..", true) + "-" + renderField(t.day, pad, "..", true)
+	switch {
+	case t.week.calculate != nil && strings.IndexByte(t.week.expr, '#') > -1:
+		//第n个星期几在第(n-1)*7+1到n*7天之间
+		index := strings.IndexByte(t.week.expr, '#')
+		weekDay, _ := strconv.Atoi(t.week.expr[:index])
+		n, _ := strconv.Atoi(t.week.expr[index+1:])
+		week = systemdWeekdays[weekDay]
+		date = fmt.Sprintf("*-%s-%02d..%02d", renderField(t.mon, pad, "..", true), (n-1)*7+1, n*7)
+	case t.week.calculate != nil:
+		//最后一个星期几在当月最后7天内
+		weekDay := 6
+		if t.week.expr != "L" {
+			weekDay, _ = strconv.Atoi(strings.TrimSuffix(t.week.expr, "L"))
+		}
+		week = systemdWeekdays[weekDay]
+		date = "*-" + renderField(t.mon, pad, "..", true) + "~07/1"
+	case t.day.expr == "L":
+		date = "*-" + renderField(t.mon, pad, "..", true) + "~01"
+	case t.day.calculate != nil:
+		return "", errors.New(fmt.Sprintf("day %s cannot be represented in %s", t.day.expr, Systemd))
+	case !isAnyField(t.week.expr):
+		//systemd的星期不支持步长，展开为列表
+		week = renderField(t.week, weekName, "..", false)
+	}
+	clock := renderField(t.hour, pad, "..", true) + ":" + renderField(t.min, pad, "..", true) + ":" + renderField(t.sec, pad, "..", true)
+	if week != "" {
+		return week + " " + date + " " + clock, nil
+	}
+	return date + " " + clock, nil
+}
+
+// systemdWeekdays systemd的星期缩写
+var systemdWeekdays = [...]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// renderField writes a parsed field back as an expression. Steps are written as start/increment
+// if step is true and they run to the end of the range, otherwise as a list of values.
+func renderField(f *field, format func(uint) string, rangeSep string, step bool) string {
+	min, max := ranges[f.name][0], ranges[f.name][1]
+	if f.isRange {
+		if f.start == min && f.end == max {
+			return "*"
+		}
+		if f.start == f.end {
+			return format(f.start)
+		}
+		return format(f.start) + rangeSep + format(f.end)
+	}
+	if step && f.increment > 0 && f.end == max {
+		return format(f.start) + "/" + strconv.Itoa(int(f.increment))
+	}
+	values := make([]string, len(f.values))
+	for i, v := range f.values {
+		values[i] = format(v)
+	}
+	return strings.Join(values, ",")
+}
+
+// mapWeekItems applies fn to the weekday numbers of a week field, leaving names, increments
+// and the n of # unchanged
+func mapWeekItems(s string, fn func(int) int) string {
+	mapNum := func(str string) string {
+		n, err := strconv.Atoi(str)
+		if err != nil {
+			return str
+		}
+		return strconv.Itoa(fn(n))
+	}
+	items := strings.Split(s, ",")
+	for i, item := range items {
+		head, tail := item, ""
+		if index := strings.IndexByte(item, '/'); index > -1 {
+			head, tail = item[:index], item[index:]
+		}
+		if index := strings.IndexByte(head, '#'); index > -1 {
+			head = mapNum(head[:index]) + head[index:]
+		} else if len(head) > 1 && strings.HasSuffix(head, "L") {
+			head = mapNum(head[:len(head)-1]) + "L"
+		} else {
+			parts := strings.Split(head, "-")
+			for j, part := range parts {
+				parts[j] = mapNum(part)
+			}
+			head = strings.Join(parts, "-")
+		}
+		items[i] = head + tail
+	}
+	return strings.Join(items, ",")
+}
+
+func isAnyField(s string) bool {
+	return s == "*" || s == "?"
+}
@@ -0,0 +1,72 @@
+package cron
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		expr     string
+		from, to Dialect
+		want     string
+		losses   int
+	}{
+		{"0 15 10 ? * MON-FRI", Native, Systemd, "Mon..Fri *-*-* 10:15:00", 0},
+		{"0 0 0 1 * ?", Native, Systemd, "*-*-01 00:00:00", 0},
+		{"0 0 18 ? * 6L", Native, Systemd, "Sat *-*~07/1 18:00:00", 0},
+		{"0 0 9 ? 5 0#2", Native, Systemd, "Sun *-05-08..14 09:00:00", 0},
+		{"0 0 23 L * ?", Native, Systemd, "*-*~01 23:00:00", 0},
+		{"0 0/5 9-17 * * ?", Native, Systemd, "*-*-* 09..17:00/5:00", 0},
+		{"0 0 12 ? * 1/2", Native, Systemd, "Mon,Wed,Fri *-*-* 12:00:00", 0},
+		{"0 0/5 14 * * ?", Native, Unix, "0/5 14 * * *", 0},
+		{"30 0-55/5 14 * * ?", Native, Kubernetes, "0,5,10,15,20,25,30,35,40,45,50,55 14 * * *", 1},
+		{"0 15 10 ? * 6L", Native, Quartz, "0 15 10 ? * 7L *", 0},
+		{"0 0 0 ? 5 0#2", Native, Quartz, "0 0 0 ? 5 1#2 *", 0},
+		{"0 15 10 15W * ?", Native, Quartz, "0 15 10 15W * ? *", 0},
+		{"*/5 9-17 * * 1-5", Unix, Quartz, "0 0/5 9-17 ? * 2-6 *", 0},
+		{"0 0 * * 5-7", Unix, Native, "0 0 0 ? * 5,6,0", 0},
+		{"@daily", Kubernetes, Systemd, "*-*-* 00:00:00", 0},
+		{"0 15 10 ? * 2-6 2027", Quartz, Unix, "15 10 * * 1-5", 1},
+		{"0 0 12 ? * L", Quartz, Native, "0 0 12 ? * 6", 0},
+		{"Mon..Fri *-*-* 09:00:00", Systemd, Native, "0 0 9 ? * 1-5", 0},
+		{"Sat *-*-1..7 18:00", Systemd, Quartz, "0 0 18 ? * 7#1 *", 0},
+		{"*-*~01 23:00", Systemd, Native, "0 0 23 L * ?", 0},
+		{"Sat *-*~07/1 18:00:00", Systemd, Native, "0 0 18 ? * 6L", 0},
+		{"*:0/15", Systemd, Unix, "0/15 * * * *", 0},
+		{"weekly", Systemd, Kubernetes, "0 0 * * 1", 0},
+		{"2027-01,07-01 06:00 UTC", Systemd, Native, "0 0 6 1 1,7 ?", 2},
+	}
+	for _, c := range cases {
+		got, losses, err := Convert(c.expr, c.from, c.to)
+		if err != nil {
+			t.Errorf("%s %s to %s: %v", c.expr, c.from, c.to, err)
+			continue
+		}
+		if got != c.want || len(losses) != c.losses {
+			t.Errorf("%s %s to %s = %q %v, want %q with %d losses", c.expr, c.from, c.to, got, losses, c.want, c.losses)
+		}
+	}
+
+	for _, c := range []struct {
+		expr     string
+		from, to Dialect
+	}{
+		{"0 0 23 L * ?", Native, Unix},
+		{"0 15 10 LW * ?", Native, Systemd},
+		{"0 0 9 3BD * ?", Native, Quartz},
+		{"0 0 0 ? * 1#1", Native, Kubernetes},
+		{"0 0 1 * 1", Unix, Native},
+		{"Mon *-*-15 00:00", Systemd, Native},
+		{"*-*~03 00:00", Systemd, Native},
+	} {
+		if _, _, err := Convert(c.expr, c.from, c.to); err == nil {
+			t.Errorf("%s %s to %s: expected an error", c.expr, c.from, c.to)
+		}
+	}
+
+	_, losses, _ := Convert("0/250 0 0 * * * ?", Native, Unix, WithMilliseconds())
+	if !reflect.DeepEqual(losses, []string{"ms 0/250 dropped"}) {
+		t.Errorf("losses = %v", losses)
+	}
+}
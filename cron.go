@@ -40,9 +40,15 @@ import (
 //0 0 23 L * ? 每月最后一天23点执行一次
 //0 15 10 LW * ? 每月最后一个工作日的上午10:15触发
 //0 15 10 15W * ? 每月15号之前最近一个工作日的上午10:15触发
+//0 0 9 3BD * ? 每月第3个工作日上午9点触发
+//0 0 18 -2BD * ? 每月倒数第2个工作日下午6点触发
+//
+//H H(2-5) * * * ? 每天2-5点之间的某一分某一秒触发，按任务名哈希分散
+//0 0~30 * * * ? 每小时0-30分之间随机的某一分触发
 
 const (
-	maxDepth  = 10
+	maxYears  = 30
+	msField   = "ms"
 	secField  = "sec"
 	minField  = "min"
 	hourField = "hour"
@@ -53,6 +59,7 @@ const (
 
 var (
 	ranges = map[string][]uint{
+		msField:   {0, 999},
 		secField:  {0, 59},
 		minField:  {0, 59},
 		hourField: {0, 23},
@@ -87,156 +94,158 @@ var (
 
 /*
 字段	允许值	允许的特殊字符
-秒	0-59	– * / ,
-分	0-59	– * / ,
-小时	0-23	– * / ,
-日期	1-31	– * ? / , L W
-月份	1-12 或者 JAN-DEC	– * / ,
-星期	0-6 或者 SUN-SAT	– * ? / , L #
+秒	0-59	– * / , H ~
+分	0-59	– * / , H ~
+小时	0-23	– * / , H ~
+日期	1-31	– * ? / , L W BD H ~
+月份	1-12 或者 JAN-DEC	– * / , H ~
+星期	0-6 或者 SUN-SAT	– * ? / , L # H ~
 */
 type trigger struct {
-	cron string
-	sec  *field
-	min  *field
-	hour *field
-	day  *field
-	mon  *field
-	week *field
+	cron     string
+	millis   bool
+	ms       *field
+	sec      *field
+	min      *field
+	hour     *field
+	day      *field
+	mon      *field
+	week     *field
+	holidays Calendar
+	hashKey  string
 }
 type field struct {
 	name      string
+	expr      string
 	isRange   bool
 	start     uint
 	end       uint
 	increment uint
 	values    []uint
-	calculate func(year, month int) bool
+	//L W BD #等按年月算出当月的那一天，不修改字段，解析后的trigger可以并发使用
+	calculate func(year, month int) (day int, ok bool)
 }
 
-func newTrigger(cronExpression string) (t *trigger, err error) {
+func newTrigger(cronExpression string, opts ...ParseOption) (t *trigger, err error) {
 	t = new(trigger)
 	t.cron = cronExpression
+	for _, opt := range opts {
+		opt(t)
+	}
 	err = t.parse()
 	return
 }
 
-// calculate next time to run. returns zero time(time.Time{}) if recursion call deep more than maxDepth
-func (t *trigger) next(now time.Time, depth ...uint8) *time.Time {
-	var deep uint8
-	if len(depth) > 0 {
-		deep = depth[0]
-		if deep > maxDepth {
-			return &time.Time{}
-		}
-	}
-	deep++
-	year := uint(now.Year())
-	month := uint(now.Month())
-	weekDay := uint(now.Weekday())
-	day := uint(now.Day())
-	hour := uint(now.Hour())
-	min := uint(now.Minute())
-	sec := uint(now.Second())
-	var (
-		nextYear    = uint(now.Year())
-		nextMonth   uint
-		nextWeekDay uint
-		nextDay     uint
-		nextHour    uint
-		nextMin     uint
-		nextSec     uint
-		isFind      bool
-	)
-	//月
-	if t.mon.isRange {
-		nextMonth, isFind = getRangeNextValue(t.mon.start, t.mon.end, month)
-	} else {
-		nextMonth, isFind = getIncreaseNextValue(t.mon.values, month)
-	}
-	if !isFind {
-		nextYear++
-		return t.next(time.Date(int(nextYear), time.Month(nextMonth), 1, 0, 0, 0, 0, time.Local), deep)
+// isBusinessDay 周一到周五且不是假日
+func (t *trigger) isBusinessDay(d time.Time) bool {
+	return isWorkDay(d) && (t.holidays == nil || !t.holidays.Excluded(midnight(d)))
+}
+
+// calculate next time to run at or after now. returns zero time(time.Time{}) if there is none within maxYears
+func (t *trigger) next(now time.Time) *time.Time {
+	now = now.In(time.Local)
+	if sec := now.Truncate(time.Second); sec.Before(now) {
+		now = sec.Add(time.Second)
 	}
-	//星期
-	if t.week.calculate != nil || t.day.calculate != nil {
-		if t.week.calculate != nil {
-			//计算星期和日
-			isFind = t.week.calculate(int(nextYear), int(nextMonth))
-		} else {
-			//计算日
-			isFind = t.day.calculate(int(nextYear), int(nextMonth))
-		}
-		nextDay = t.day.start
-		//如果算出来的要小于当前日期
-		if !isFind || time.Date(int(nextYear), time.Month(nextMonth), int(nextDay), int(hour), int(min), int(sec), now.Nanosecond(), time.Local).Before(now) {
-			return t.next(time.Date(int(nextYear), time.Month(nextMonth), 1, 0, 0, 0, 0, time.Local).AddDate(0, 1, 0), deep)
+	year, month, day := now.Date()
+	yearLimit := year + maxYears
+	//按日历日期逐日查找，夏令时可能跳过本地的0点，不能用本地时间的0点加一天
+	for year <= yearLimit {
+		if !t.mon.match(uint(month)) {
+			year, month, day = time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC).Date()
+			continue
 		}
-	} else {
-		//如果不是当月，则星期和日从起始值开始计算
-		startWeekDay := weekDay
-		startDay := day
-		if !(nextYear == year && nextMonth == month) {
-			startWeekDay = 0
-			startDay = 1
-		}
-		if t.week.isRange {
-			nextWeekDay, isFind = getRangeNextValue(t.week.start, t.week.end, startWeekDay)
-		} else {
-			nextWeekDay, isFind = getIncreaseNextValue(t.week.values, startWeekDay)
+		if t.matchDay(time.Date(year, month, day, 12, 0, 0, 0, time.Local)) {
+			if next, ok := t.nextClock(year, month, day, now); ok {
+				return &next
+			}
 		}
-		if !isFind {
-			//下一个nextWeekDay
-			tempDate := getMonthAfterLatestWeek(int(nextYear), int(nextMonth), int(startDay), int(nextWeekDay))
-			return t.next(tempDate, deep)
+		year, month, day = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC).Date()
+	}
+	return &time.Time{}
+}
+
+// nextClock returns the first time of the date at or after now matching the hour, minute and
+// second fields. Local times skipped by daylight saving time do not exist and are not returned.
+func (t *trigger) nextClock(year int, month time.Month, day int, now time.Time) (time.Time, bool) {
+	for h, ok := t.hour.nextValue(0); ok; h, ok = t.hour.nextValue(h + 1) {
+		if end := time.Date(year, month, day, int(h), 59, 59, 0, time.Local); end.Before(now) {
+			continue
 		}
-		//日,找出和星期对应的日
-		if t.day.isRange {
-			nextDay = getRangeDayNextValue(t.day.start, t.day.end, nextYear, nextMonth, startDay, nextWeekDay)
-		} else {
-			nextDay = getIncreaseDayNextValue(t.day.values, int(nextYear), nextMonth, startDay, nextWeekDay)
+		for m, ok := t.min.nextValue(0); ok; m, ok = t.min.nextValue(m + 1) {
+			if end := time.Date(year, month, day, int(h), int(m), 59, 0, time.Local); end.Before(now) {
+				continue
+			}
+			for s, ok := t.sec.nextValue(0); ok; s, ok = t.sec.nextValue(s + 1) {
+				next := time.Date(year, month, day, int(h), int(m), int(s), 0, time.Local)
+				if next.Before(now) {
+					continue
+				}
+				if next.Hour() != int(h) || next.Minute() != int(m) || next.Second() != int(s) {
+					break
+				}
+				return next, true
+			}
 		}
 	}
-	//时,如果不是当前日,时从起始值算起
-	startHour := hour
-	if !(nextYear == year && nextMonth == month && nextDay == day) {
-		startHour = 0
-	}
-	if t.hour.isRange {
-		nextHour, isFind = getRangeNextValue(t.hour.start, t.hour.end, startHour)
-	} else {
-		nextHour, isFind = getIncreaseNextValue(t.hour.values, startHour)
-	}
-	if !isFind {
-		return t.next(time.Date(int(nextYear), time.Month(nextMonth), int(nextDay), int(nextHour), 0, 0, 0, time.Local).Add(24*time.Hour), deep)
+	return time.Time{}, false
+}
+
+// matchDay reports whether the day and week fields both match the date of d
+func (t *trigger) matchDay(d time.Time) bool {
+	year, month, day := d.Date()
+	if t.week.calculate != nil {
+		//星期算出当月的日期，日是*或?
+		resolved, ok := t.week.calculate(year, int(month))
+		return ok && day == resolved
 	}
-	//分,如果不是当前小时,分从起始值算起
-	startMin := min
-	if !(nextYear == year && nextMonth == month && nextDay == day && nextHour == hour) {
-		startMin = 0
+	if t.day.calculate != nil {
+		resolved, ok := t.day.calculate(year, int(month))
+		return ok && day == resolved && t.week.match(uint(d.Weekday()))
 	}
-	if t.min.isRange {
-		nextMin, isFind = getRangeNextValue(t.min.start, t.min.end, startMin)
-	} else {
-		nextMin, isFind = getIncreaseNextValue(t.min.values, startMin)
+	return t.day.match(uint(day)) && t.week.match(uint(d.Weekday()))
+}
+
+// nextValue returns the smallest value of the field not less than from
+func (f *field) nextValue(from uint) (uint, bool) {
+	if f.isRange {
+		if from > f.end {
+			return 0, false
+		}
+		if from < f.start {
+			return f.start, true
+		}
+		return from, true
 	}
-	if !isFind {
-		return t.next(time.Date(int(nextYear), time.Month(nextMonth), int(nextDay), int(nextHour), int(nextMin), 0, 0, time.Local).Add(time.Hour), deep)
+	for _, v := range f.values {
+		if v >= from {
+			return v, true
+		}
 	}
-	//秒,如果不是当前分钟,秒从起始值算起
-	startSec := sec
-	if !(nextYear == year && nextMonth == month && nextDay == day && nextHour == hour && nextMin == min) {
-		startSec = 0
+	return 0, false
+}
+
+// all reports whether the field matches every value of its range
+func (f *field) all() bool {
+	r := ranges[f.name]
+	for v := r[0]; v <= r[1]; v++ {
+		if !f.match(v) {
+			return false
+		}
 	}
-	if t.sec.isRange {
-		nextSec, isFind = getRangeNextValue(t.sec.start, t.sec.end, startSec)
-	} else {
-		nextSec, isFind = getIncreaseNextValue(t.sec.values, startSec)
+	return true
+}
+
+func (f *field) match(value uint) bool {
+	if f.isRange {
+		return value >= f.start && value <= f.end
 	}
-	if !isFind {
-		return t.next(time.Date(int(nextYear), time.Month(nextMonth), int(nextDay), int(nextHour), int(nextMin), int(nextSec), 0, time.Local).Add(time.Minute), deep)
+	for _, v := range f.values {
+		if v == value {
+			return true
+		}
 	}
-	nextTime := time.Date(int(nextYear), time.Month(nextMonth), int(nextDay), int(nextHour), int(nextMin), int(nextSec), 0, time.Local)
-	return &nextTime
+	return false
 }
 
 func checkAlias(str, value string, f *field, num *uint64) error {
@@ -265,7 +274,7 @@ func (t *trigger) parserRangeField(str string, arr []string, f *field) error {
 	tempRange := ranges[f.name]
 	min := tempRange[0]
 	max := tempRange[1]
-	start0, err := strconv.ParseUint(arr[0], 10, 8)
+	start0, err := strconv.ParseUint(arr[0], 10, 16)
 	if err != nil && checkAlias(str, arr[0], f, &start0) != nil {
 		return err
 	}
@@ -273,7 +282,7 @@ func (t *trigger) parserRangeField(str string, arr []string, f *field) error {
 	if f.start < min || f.start > max {
 		return errors.New(fmt.Sprintf(f.name+" range should be in [%d,%d]", min, max))
 	}
-	end0, err := strconv.ParseUint(arr[1], 10, 8)
+	end0, err := strconv.ParseUint(arr[1], 10, 16)
 	if err != nil && checkAlias(str, arr[1], f, &end0) != nil {
 		return err
 	}
@@ -296,8 +305,17 @@ func (t *trigger) parserIncreaseField(str string, arr []string, f *field) error
 		end       = max
 		increment uint
 	)
-	if arr[0] != "*" {
-		start0, err := strconv.ParseUint(arr[0], 10, 8)
+	if index := strings.IndexByte(arr[0], '-'); index > -1 {
+		//0-55/5 在范围内递增
+		tempField := &field{name: f.name}
+		err := t.parserRangeField(str, strings.Split(arr[0], "-"), tempField)
+		if err != nil {
+			return err
+		}
+		start = tempField.start
+		end = tempField.end
+	} else if arr[0] != "*" {
+		start0, err := strconv.ParseUint(arr[0], 10, 16)
 		if err != nil {
 			return errors.New(fmt.Sprintf(f.name+" %s start:%s is not a positive integer", str, arr[0]))
 		}
@@ -306,7 +324,7 @@ func (t *trigger) parserIncreaseField(str string, arr []string, f *field) error
 			return errors.New(fmt.Sprintf(f.name+" range should be in [%d,%d]", min, max))
 		}
 	}
-	increment0, err := strconv.ParseUint(arr[1], 10, 8)
+	increment0, err := strconv.ParseUint(arr[1], 10, 16)
 	if err != nil {
 		return errors.New(fmt.Sprintf(f.name+" %s increment:%s is not a positive integer", str, arr[1]))
 	}
@@ -335,7 +353,7 @@ func (t *trigger) parserEnumField(str string, arr []string, f *field) error {
 		values  []uint
 	)
 	for i := 0; i < len(arr); i++ {
-		temp, err := strconv.ParseUint(arr[i], 10, 8)
+		temp, err := strconv.ParseUint(arr[i], 10, 16)
 		if err != nil && checkAlias(str, arr[i], f, &temp) != nil {
 			return err
 		}
@@ -359,7 +377,17 @@ func (t *trigger) parserDayField(s string) (err error) {
 		t.day.isRange = true
 		t.day.start = 1
 		t.day.end = 31
-	} else if index := strings.IndexByte(s, '-'); index > -1 {
+	} else if strings.HasSuffix(s, "BD") {
+		//3BD 当月第3个工作日，-2BD 当月倒数第2个工作日
+		n, err := strconv.Atoi(s[:len(s)-2])
+		if err != nil || n == 0 || n < -23 || n > 23 {
+			return errors.New(fmt.Sprintf("day field %s business day should be in [1,23] or [-23,-1]", s))
+		}
+		t.day.calculate = func(year, month int) (int, bool) {
+			day := getMonthBusinessDay(year, month, n, t.isBusinessDay)
+			return day, day != 0
+		}
+	} else if index := strings.IndexByte(s, '-'); index > -1 && strings.IndexByte(s, '/') < 0 {
 		tempUnitArr := strings.Split(s, "-")
 		err = t.parserRangeField(s, tempUnitArr, t.day)
 		if err != nil {
@@ -378,35 +406,27 @@ func (t *trigger) parserDayField(s string) (err error) {
 			return err
 		}
 	} else if s == "L" {
-		t.day.calculate = func(year, month int) bool {
-			start := getYearMonthDays(year, month)
-			t.day.isRange = true
-			t.day.start = uint(start)
-			t.day.end = uint(start)
-			return true
+		t.day.calculate = func(year, month int) (int, bool) {
+			return getYearMonthDays(year, month), true
 		}
 	} else if s == "LW" {
-		t.day.calculate = func(year, month int) bool {
+		t.day.calculate = func(year, month int) (int, bool) {
 			max := getYearMonthDays(year, month)
-			start := getLatestWorkDay(year, month, max).Day()
-			t.day.isRange = true
-			t.day.start = uint(start)
-			t.day.end = uint(start)
-			return true
+			tempTime := getLatestWorkDay(year, month, max, t.isBusinessDay)
+			if tempTime == nil {
+				return 0, false
+			}
+			return tempTime.Day(), true
 		}
 	} else if index = strings.IndexByte(s, 'W'); index > -1 {
 		//15W
 		day, _ := strconv.ParseUint(s[:index], 10, 8)
-		t.day.calculate = func(year, month int) bool {
-			tempTime := getLatestWorkDay(year, month, int(day))
+		t.day.calculate = func(year, month int) (int, bool) {
+			tempTime := getLatestWorkDay(year, month, int(day), t.isBusinessDay)
 			if tempTime == nil {
-				return false
+				return 0, false
 			}
-			start := tempTime.Day()
-			t.day.isRange = true
-			t.day.start = uint(start)
-			t.day.end = uint(start)
-			return true
+			return tempTime.Day(), true
 		}
 	} else {
 		day, _ := strconv.ParseUint(s, 10, 8)
@@ -427,7 +447,7 @@ func (t *trigger) parserMonField(s string) (err error) {
 		t.mon.isRange = true
 		t.mon.start = 1
 		t.mon.end = 12
-	} else if index := strings.IndexByte(s, '-'); index > -1 {
+	} else if index := strings.IndexByte(s, '-'); index > -1 && strings.IndexByte(s, '/') < 0 {
 		tempUnitArr := strings.Split(s, "-")
 		err = t.parserRangeField(s, tempUnitArr, t.mon)
 		if err != nil {
@@ -469,17 +489,16 @@ func (t *trigger) parserMonField(s string) (err error) {
 }
 
 // 星期	0-6 或者 SUN-SAT	– * ? / , L #
-func (t *trigger) parserWeekField(s string) (err error) {
+func (t *trigger) parserWeekField(s string, day string) (err error) {
 	if s == "*" || s == "?" {
-		t.week = &field{isRange: true, start: 0, end: 6}
+		t.week = &field{name: weekField, isRange: true, start: 0, end: 6}
 	} else {
 		t.week = &field{name: weekField}
 		//日必须为*或者?
-		arr := strings.Split(t.cron, " ")
-		if arr[3] != "*" && arr[3] != "?" {
+		if day != "*" && day != "?" {
 			return errors.New("day field must be * or ? when the week is specific")
 		}
-		if index := strings.IndexByte(s, '-'); index > -1 {
+		if index := strings.IndexByte(s, '-'); index > -1 && strings.IndexByte(s, '/') < 0 {
 			tempUnitArr := strings.Split(s, "-")
 			err = t.parserRangeField(s, tempUnitArr, t.week)
 			if err != nil {
@@ -512,15 +531,8 @@ func (t *trigger) parserWeekField(s string) (err error) {
 				}
 				weekNum = int(start)
 			}
-			t.week.calculate = func(year, month int) bool {
-				now := getMonthLatestWeek(year, month, weekNum)
-				t.week.isRange = true
-				t.week.start = uint(now.Weekday())
-				t.week.end = uint(now.Weekday())
-				t.day.isRange = true
-				t.day.start = uint(now.Day())
-				t.day.end = uint(now.Day())
-				return true
+			t.week.calculate = func(year, month int) (int, bool) {
+				return getMonthLatestWeek(year, month, weekNum).Day(), true
 			}
 		} else if index = strings.IndexByte(s, '#'); index > -1 {
 			// 0#2每月第2个星期0
@@ -538,18 +550,12 @@ func (t *trigger) parserWeekField(s string) (err error) {
 			if weekNum < 1 || weekNum > 4 {
 				return errors.New(fmt.Sprintf("week:%s weekNum should be in [1,4]", s))
 			}
-			t.week.calculate = func(year, month int) bool {
+			t.week.calculate = func(year, month int) (int, bool) {
 				now := getMonthWeekByWeekNumDay(year, month, uint(weekNum), uint(weekDay))
 				if now == nil {
-					return false
+					return 0, false
 				}
-				t.week.isRange = true
-				t.week.start = uint(now.Weekday())
-				t.week.end = uint(now.Weekday())
-				t.day.isRange = true
-				t.day.start = uint(now.Day())
-				t.day.end = uint(now.Day())
-				return true
+				return now.Day(), true
 			}
 		} else {
 			var start uint
@@ -577,13 +583,32 @@ func (t *trigger) parserWeekField(s string) (err error) {
 
 func (t *trigger) parse() (err error) {
 	arr := strings.Split(t.cron, " ")
-	if len(arr) != 6 {
-		return errors.New("cronExpression's fields count is not 6")
+	names := []string{secField, minField, hourField, dayField, monField, weekField}
+	if t.millis {
+		names = append([]string{msField}, names...)
+	}
+	if len(arr) != len(names) {
+		return errors.New(fmt.Sprintf("cronExpression's fields count is not %d", len(names)))
+	}
+	//H和~替换为具体的值
+	for i, name := range names {
+		arr[i], err = t.spreadField(name, arr[i])
+		if err != nil {
+			return err
+		}
+	}
+	//解析毫秒
+	if t.millis {
+		t.ms = &field{name: msField, expr: arr[0]}
+		err = t.parserTimeField(arr[0], t.ms)
+		if err != nil {
+			return err
+		}
+		arr = arr[1:]
 	}
 	//解析秒，分，时
 	units := []string{secField, minField, hourField}
 	for i, unit := range units {
-		str := arr[i]
 		f := &field{name: unit}
 		switch i {
 		case 0:
@@ -593,39 +618,9 @@ func (t *trigger) parse() (err error) {
 		case 2:
 			t.hour = f
 		}
-		if str == "*" {
-			f.isRange = true
-			f.start = 0
-			f.end = ranges[f.name][1]
-		} else if index := strings.IndexByte(str, '-'); index > -1 {
-			tempUnitArr := strings.Split(str, "-")
-			err = t.parserRangeField(str, tempUnitArr, f)
-			if err != nil {
-				return err
-			}
-		} else if index = strings.IndexByte(str, '/'); index > -1 {
-			tempUnitArr := strings.Split(str, "/")
-			err = t.parserIncreaseField(str, tempUnitArr, f)
-			if err != nil {
-				return err
-			}
-		} else if index = strings.IndexByte(str, ','); index > -1 {
-			tempUnitArr := strings.Split(str, ",")
-			err = t.parserEnumField(str, tempUnitArr, f)
-			if err != nil {
-				return err
-			}
-		} else {
-			start, err := strconv.ParseUint(str, 10, 8)
-			if err != nil {
-				return errors.New(fmt.Sprintf(unit+" %s is not a positive integer", str))
-			}
-			if start < 0 || start > 59 {
-				return errors.New(unit + " range should be [0,59]")
-			}
-			f.isRange = true
-			f.start = uint(start)
-			f.end = uint(start)
+		err = t.parserTimeField(arr[i], f)
+		if err != nil {
+			return err
 		}
 	}
 	//解析日
@@ -639,56 +634,54 @@ func (t *trigger) parse() (err error) {
 		return err
 	}
 	//解析周
-	err = t.parserWeekField(arr[5])
+	err = t.parserWeekField(arr[5], arr[3])
 	if err != nil {
 		return err
 	}
+	//保存替换H和~之后的字段，用于Explain
+	for i, f := range []*field{t.sec, t.min, t.hour, t.day, t.mon, t.week} {
+		f.expr = arr[i]
+	}
 	return
 }
 
-func getRangeNextValue(start uint, end uint, nowValue uint) (nextValue uint, isFind bool) {
-	for i := start; i <= end; i++ {
-		if i >= nowValue {
-			nextValue = i
-			isFind = true
-			return
+// 毫秒，秒，分，时	– * / ,
+func (t *trigger) parserTimeField(str string, f *field) (err error) {
+	if str == "*" {
+		f.isRange = true
+		f.start = 0
+		f.end = ranges[f.name][1]
+	} else if index := strings.IndexByte(str, '-'); index > -1 && strings.IndexByte(str, '/') < 0 {
+		tempUnitArr := strings.Split(str, "-")
+		err = t.parserRangeField(str, tempUnitArr, f)
+		if err != nil {
+			return err
 		}
-	}
-	nextValue = start
-	return
-}
-func getIncreaseNextValue(values []uint, nowValue uint) (nextValue uint, isFind bool) {
-	for _, value := range values {
-		if value >= nowValue {
-			nextValue = value
-			isFind = true
-			return
+	} else if index = strings.IndexByte(str, '/'); index > -1 {
+		tempUnitArr := strings.Split(str, "/")
+		err = t.parserIncreaseField(str, tempUnitArr, f)
+		if err != nil {
+			return err
 		}
-	}
-	nextValue = values[0]
-	return
-}
-func getRangeDayNextValue(start uint, end uint, year, nextMonth uint, day uint, nextWeekDay uint) uint {
-	for i := start; i <= end; i++ {
-		if i >= day {
-			tempDate := time.Date(int(year), time.Month(nextMonth), int(i), 0, 0, 0, 0, time.Local)
-			if uint(tempDate.Weekday()) == nextWeekDay {
-				return i
-			}
+	} else if index = strings.IndexByte(str, ','); index > -1 {
+		tempUnitArr := strings.Split(str, ",")
+		err = t.parserEnumField(str, tempUnitArr, f)
+		if err != nil {
+			return err
 		}
-	}
-	return 0
-}
-func getIncreaseDayNextValue(values []uint, year int, nextMonth uint, day uint, nextWeekDay uint) uint {
-	for _, i := range values {
-		if i >= day {
-			tempDate := time.Date(year, time.Month(nextMonth), int(i), 0, 0, 0, 0, time.Local)
-			if uint(tempDate.Weekday()) == nextWeekDay {
-				return i
-			}
+	} else {
+		start, err := strconv.ParseUint(str, 10, 16)
+		if err != nil {
+			return errors.New(fmt.Sprintf(f.name+" %s is not a positive integer", str))
 		}
+		if uint(start) > ranges[f.name][1] {
+			return errors.New(fmt.Sprintf(f.name+" range should be [0,%d]", ranges[f.name][1]))
+		}
+		f.isRange = true
+		f.start = uint(start)
+		f.end = uint(start)
 	}
-	return 0
+	return nil
 }
 
 func getYearMonthDays(year int, month int) int {
@@ -705,33 +698,59 @@ func getYearMonthDays(year int, month int) int {
 		}
 	}
 }
-func getLatestWorkDay(year int, month int, day int) *time.Time {
-	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
-	for wd := t.Weekday(); wd == 0 || wd == 6; wd = t.Weekday() {
-		t = t.AddDate(0, 0, -1)
-	}
+
+// getLatestWorkDay returns the work day at or before the day. The date helpers use noon,
+// midnight may not exist on days DST starts
+func getLatestWorkDay(year int, month int, day int, isBusinessDay func(time.Time) bool) *time.Time {
+	t := time.Date(year, time.Month(month), day, 12, 0, 0, 0, time.Local)
+	t = getWorkDay(t, -1, isBusinessDay)
 	if int(t.Month()) != month {
 		return nil
 	}
 	return &t
 }
-func getMonthLatestWeek(year, month, weekDay int) time.Time {
-	max := getYearMonthDays(year, month)
-	t := time.Date(year, time.Month(month), max, 0, 0, 0, 0, time.Local)
-	for wd := t.Weekday(); int(wd) != weekDay; wd = t.Weekday() {
-		t = t.AddDate(0, 0, -1)
+
+// isWorkDay 周一到周五为工作日
+func isWorkDay(t time.Time) bool {
+	wd := t.Weekday()
+	return wd != time.Saturday && wd != time.Sunday
+}
+
+// getWorkDay returns t if it is a work day, otherwise the nearest work day in direction step (1 or -1)
+func getWorkDay(t time.Time, step int, isBusinessDay func(time.Time) bool) time.Time {
+	for i := 0; !isBusinessDay(t) && i < 366; i++ {
+		t = t.AddDate(0, 0, step)
 	}
 	return t
 }
-func getMonthAfterLatestWeek(year, month, day, weekDay int) time.Time {
-	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+
+// getMonthBusinessDay returns the day of the n-th business day of the month, counted from the
+// end of the month if n is negative, or 0 if the month has fewer business days
+func getMonthBusinessDay(year, month, n int, isBusinessDay func(time.Time) bool) int {
+	max := getYearMonthDays(year, month)
+	day, step := 1, 1
+	if n < 0 {
+		day, step, n = max, -1, -n
+	}
+	for ; day >= 1 && day <= max; day += step {
+		if isBusinessDay(time.Date(year, time.Month(month), day, 12, 0, 0, 0, time.Local)) {
+			if n--; n == 0 {
+				return day
+			}
+		}
+	}
+	return 0
+}
+func getMonthLatestWeek(year, month, weekDay int) time.Time {
+	max := getYearMonthDays(year, month)
+	t := time.Date(year, time.Month(month), max, 12, 0, 0, 0, time.Local)
 	for wd := t.Weekday(); int(wd) != weekDay; wd = t.Weekday() {
-		t = t.AddDate(0, 0, 1)
+		t = t.AddDate(0, 0, -1)
 	}
 	return t
 }
 func getMonthWeekByWeekNumDay(year int, month int, weekNum uint, weekDay uint) *time.Time {
-	t := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	t := time.Date(year, time.Month(month), 1, 12, 0, 0, 0, time.Local)
 	var tempWeekNum uint = 0
 	for tempWeekDay := t.Weekday(); int(t.Month()) == month; tempWeekDay = t.Weekday() {
 		if uint(tempWeekDay) == weekDay {
@@ -746,118 +765,257 @@ func getMonthWeekByWeekNumDay(year int, month int, weekNum uint, weekDay uint) *
 }
 
 type job struct {
-	id       uint
-	t        *trigger
-	fun      func()
-	nextTime *time.Time
-	running  bool
+	id        uint
+	name      string
+	s         Schedule
+	runner    Job
+	nextTime  *time.Time
+	running   int
+	calendars calendars
+	ctx       context.Context
+	cancel    context.CancelFunc
+	history   []Execution
+	overlap   OverlapPolicy
+	//QueueOne等待执行的触发
+	pending *queued
+	//正在执行的任务的cancel
+	cancels map[uint64]context.CancelFunc
+	seq     uint64
+	//在队列中等待的触发次数
+	queued int
+	//占用worker的执行，包括超时后不再等待但还没返回的
+	workers        int
+	maxConcurrency int
+	priority       int
+	timeout        time.Duration
+	retry          *RetryPolicy
+
+	misfire          MisfirePolicy
+	misfireThreshold time.Duration
+}
+
+// JobOption configures a job added to the Scheduler.
+type JobOption func(j *job)
+
+// WithName gives the job a human readable name used in exports and reports, and as the hash
+// key of H in its cron expressions.
+func WithName(name string) JobOption {
+	return func(j *job) {
+		j.name = name
+	}
+}
+
+// WithCalendars attaches exclusion calendars to the job. Fire times falling into a period
+// excluded by any of the calendars are skipped.
+func WithCalendars(cals ...Calendar) JobOption {
+	return func(j *job) {
+		j.calendars = append(j.calendars, cals...)
+	}
+}
+
+// jobParseOptions uses the job name as the hash key of H in the job's expressions
+func jobParseOptions(opts []JobOption) []ParseOption {
+	j := new(job)
+	for _, opt := range opts {
+		opt(j)
+	}
+	if j.name == "" {
+		return nil
+	}
+	return []ParseOption{WithHashKey(j.name)}
 }
 
-func newJob(cronExpression string, f func()) (j *job, err error) {
+func newJob(s Schedule, runner Job, opts ...JobOption) (j *job) {
 	j = new(job)
-	j.t, err = newTrigger(cronExpression)
-	if err != nil {
-		return nil, err
+	j.s = s
+	j.runner = runner
+	j.cancels = make(map[uint64]context.CancelFunc)
+	for _, opt := range opts {
+		opt(j)
 	}
-	j.fun = f
-	j.nextTime = j.t.next(time.Now())
 	return
 }
 func (j *job) next(t time.Time) *time.Time {
 	if j.nextTime != nil && j.nextTime.After(t) {
 		return j.nextTime
 	}
-	j.nextTime = j.t.next(t)
+	j.nextTime = j.nextIncluded(t)
 	return j.nextTime
 }
 
-func (j *job) run() {
-	j.running = true
+// nextIncluded calculates the next fire time of the schedule which is not excluded by the job's calendars.
+// It returns the zero time if there is none within maxYears.
+func (j *job) nextIncluded(t time.Time) *time.Time {
+	nextTime := j.s.Next(t)
+	limit := t.AddDate(maxYears, 0, 0)
+	for len(j.calendars) > 0 && !nextTime.IsZero() && j.calendars.Excluded(nextTime) {
+		if nextTime.After(limit) {
+			return &time.Time{}
+		}
+		nextTime = nextFrom(j.s, j.calendars.includedAfter(nextTime))
+	}
+	return &nextTime
+}
+
+func (j *job) run(ctx context.Context) (err error) {
 	defer func() {
-		j.running = false
-		if err := recover(); err != nil {
-			debug.PrintStack()
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
 		}
 	}()
-	j.fun()
+	return j.runner.Run(ctx)
 }
 
 type Scheduler struct {
-	timer    *time.Timer
 	jobMap   map[uint]*job
 	jobs     []*job
 	lock     sync.Mutex
 	id       uint
 	running  bool
-	runState bool
-	stop     chan struct{}
-	jobChan  chan struct{}
+	started  bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wake     chan struct{}
 	wg       sync.WaitGroup
+	handlers []EventHandler
+	clock    Clock
+
+	panicHandler PanicHandler
+	errorHandler ErrorHandler
+
+	//worker池
+	cond           *sync.Cond
+	maxConcurrency int
+	active         int
+	queue          []*queued
+	queueSeq       uint64
+	queueLimit     int
+	queuePolicy    QueuePolicy
+
+	defaultTimeout time.Duration
 }
 
-func New() (s *Scheduler) {
+// Option configures a Scheduler.
+type Option func(c *Scheduler)
+
+func New(opts ...Option) (s *Scheduler) {
 	s = new(Scheduler)
 	s.jobMap = make(map[uint]*job, 0)
-	s.stop = make(chan struct{}, 1)
-	s.jobChan = make(chan struct{}, 1)
+	s.wake = make(chan struct{}, 1)
+	s.panicHandler = defaultPanicHandler
+	s.cond = sync.NewCond(&s.lock)
+	s.clock = realClock{}
+	for _, opt := range opts {
+		opt(s)
+	}
 	return
 }
-func (c *Scheduler) AddJob(cronExpression string, f func()) (id uint, err error) {
-	j, err := newJob(cronExpression, f)
+func (c *Scheduler) AddJob(cronExpression string, f func(), opts ...JobOption) (id uint, err error) {
+	return c.AddCronJob(cronExpression, plainJob(f), opts...)
+}
+
+// AddFunc adds a job running f at the times of the cron expression. The context passed to f is
+// cancelled when the scheduler stops or the job is removed.
+func (c *Scheduler) AddFunc(cronExpression string, f func(ctx context.Context) error, opts ...JobOption) (id uint, err error) {
+	return c.AddCronJob(cronExpression, FuncJob(f), opts...)
+}
+
+// AddCronJob adds a job running j at the times of the cron expression.
+func (c *Scheduler) AddCronJob(cronExpression string, j Job, opts ...JobOption) (id uint, err error) {
+	s, err := Parse(cronExpression, jobParseOptions(opts)...)
 	if err != nil {
 		return 0, err
 	}
+	return c.AddScheduleJob(s, j, opts...), nil
+}
+
+// AddJobExpressions adds one job running f at the times of any of the cron expressions.
+// Times matched by several expressions run f only once.
+func (c *Scheduler) AddJobExpressions(cronExpressions []string, f func(), opts ...JobOption) (id uint, err error) {
+	if len(cronExpressions) == 0 {
+		return 0, errors.New("cronExpressions should not be empty")
+	}
+	schedules := make([]Schedule, 0, len(cronExpressions))
+	for _, cronExpression := range cronExpressions {
+		s, err := Parse(cronExpression, jobParseOptions(opts)...)
+		if err != nil {
+			return 0, errors.New(fmt.Sprintf("cronExpression %s: %s", cronExpression, err))
+		}
+		schedules = append(schedules, s)
+	}
+	return c.AddSchedule(Union(schedules...), f, opts...), nil
+}
+
+// AddSchedule adds a job running f at the times of the schedule s, e.g. a composite schedule built with Union.
+func (c *Scheduler) AddSchedule(s Schedule, f func(), opts ...JobOption) (id uint) {
+	return c.AddScheduleJob(s, plainJob(f), opts...)
+}
+
+// AddScheduleJob adds a job running j at the times of the schedule s.
+func (c *Scheduler) AddScheduleJob(s Schedule, j Job, opts ...JobOption) (id uint) {
+	jb := newJob(s, j, opts...)
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	jb.nextTime = jb.nextIncluded(c.clock.Now())
 	c.id++
-	j.id = c.id
-	c.jobs = append(c.jobs, j)
-	c.jobMap[j.id] = j
-	if c.running {
-		c.jobChan <- struct{}{}
-	}
-	return j.id, nil
+	jb.id = c.id
+	c.jobs = append(c.jobs, jb)
+	c.jobMap[jb.id] = jb
+	c.wakeUp()
+	return jb.id
 }
+
+// Remove removes the job id and cancels the context of its running executions.
 func (c *Scheduler) Remove(id uint) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	_, ok := c.jobMap[id]
+	j, ok := c.jobMap[id]
 	if !ok {
 		return
 	}
 	delete(c.jobMap, id)
-	if len(c.jobs) == 1 {
-		c.stop <- struct{}{}
-		c.runState = false
-		c.jobs = c.jobs[:0]
-		return
-	}
-	var i int
-	for i = 0; i < len(c.jobs); i++ {
+	for i := 0; i < len(c.jobs); i++ {
 		if c.jobs[i].id == id {
+			c.jobs = append(c.jobs[:i], c.jobs[i+1:]...)
 			break
 		}
 	}
-	c.jobs = append(c.jobs[:i], c.jobs[i+1:]...)
+	if j.cancel != nil {
+		j.cancel()
+	}
+	c.dropQueued(j)
+	c.wakeUp()
 }
+
+// Start starts running the jobs in the background.
 func (c *Scheduler) Start() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	if c.running {
 		return
 	}
 	c.running = true
-	go c.watchJobAdding()
-	if len(c.jobs) == 0 {
-		return
+	//第一次启动前的触发时间不算错过
+	if !c.started {
+		c.started = true
+		now := c.clock.Now()
+		for _, j := range c.jobs {
+			j.nextTime = j.nextIncluded(now)
+		}
 	}
-	c.run()
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	go c.run(c.ctx)
 }
+
+// Stop stops the scheduler and cancels the context of the running executions. The returned
+// context is done when they have all returned.
 func (c *Scheduler) Stop() (ctx context.Context) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	if c.running {
-		close(c.stop)
-		close(c.jobChan)
+		c.cancel()
 		c.running = false
+		c.dropQueued(nil)
 	}
 	var cancel context.CancelFunc
 	ctx, cancel = context.WithCancel(context.Background())
@@ -868,62 +1026,175 @@ func (c *Scheduler) Stop() (ctx context.Context) {
 	return
 }
 
-func (c *Scheduler) sortJob(now time.Time) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	sort.Slice(c.jobs, func(i, j int) bool {
-		nextTime0 := c.jobs[i].next(now)
-		nextTime1 := c.jobs[j].next(now)
-		return nextTime0.Before(*nextTime1)
-	})
+// wakeUp makes the run loop recalculate the next fire time, called with c.lock held
+func (c *Scheduler) wakeUp() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// nextFire returns the earliest fire time of all jobs, called with c.lock held
+func (c *Scheduler) nextFire() (next time.Time) {
+	for _, j := range c.jobs {
+		if t := *j.nextTime; !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return
 }
 
-func (c *Scheduler) run() {
-	now := time.Now()
-	nextTime := &now
-	nextNextTime := *nextTime
+func (c *Scheduler) run(ctx context.Context) {
 	for {
-		c.sortJob(nextNextTime)
-		if len(c.jobs) == 0 {
-			return
+		c.lock.Lock()
+		next := c.nextFire()
+		c.lock.Unlock()
+		//没有任务时等待添加
+		var (
+			timer Timer
+			fire  <-chan time.Time
+		)
+		if !next.IsZero() {
+			timer = c.clock.NewTimer(next.Sub(c.clock.Now()))
+			fire = timer.C()
 		}
-		now = time.Now()
-		nextTime = c.jobs[0].nextTime
-		nextNextTime = nextTime.Add(time.Second)
-		c.timer = time.NewTimer(nextTime.Sub(now))
 		select {
-		case _ = <-c.timer.C:
-			for i, tempNextTime := 0, c.jobs[0].nextTime; tempNextTime.Equal(*nextTime) && i < len(c.jobs); {
-				c.runJob(c.jobs[i])
-				c.jobs[i].next(nextNextTime)
-				i++
-				if i < len(c.jobs) {
-					tempNextTime = c.jobs[i].nextTime
-				}
-			}
-			c.timer.Stop()
-		case <-c.stop:
-			c.timer.Stop()
+		case <-fire:
+			c.runDue(ctx, c.clock.Now())
+		case <-c.wake:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
 			return
 		}
 	}
 }
-func (c *Scheduler) watchJobAdding() {
-	for c.running {
-		select {
-		case <-c.jobChan:
-			if c.runState {
-				c.stop <- struct{}{}
-				c.runState = false
-			}
-			go c.run()
+
+// runDue runs the jobs whose fire time has come
+func (c *Scheduler) runDue(ctx context.Context, now time.Time) {
+	c.lock.Lock()
+	//已经Stop
+	if ctx.Err() != nil {
+		c.lock.Unlock()
+		return
+	}
+	var events []*Event
+	//QueueBlock等待时c.jobs可能被修改
+	jobs := append([]*job(nil), c.jobs...)
+	for _, j := range jobs {
+		if c.jobMap[j.id] != j || !c.running {
+			continue
 		}
+		if fireTime := *j.nextTime; !fireTime.IsZero() && !fireTime.After(now) {
+			events = append(events, c.fireDue(j, now)...)
+		}
+	}
+	c.lock.Unlock()
+	//事件处理函数可能调用Scheduler的方法，解锁后再通知
+	for _, e := range events {
+		c.emit(*e)
 	}
 }
-func (c *Scheduler) runJob(j *job) {
+
+// start starts an execution of j, called with c.lock held
+func (c *Scheduler) start(j *job, fireTime time.Time, attempt int) {
+	if j.ctx == nil || j.ctx.Err() != nil {
+		j.ctx, j.cancel = context.WithCancel(c.ctx)
+	}
+	var (
+		ctx     context.Context
+		cancel  context.CancelFunc
+		expired <-chan struct{}
+		stop    = func() {}
+	)
+	if timeout := c.timeoutOf(j); timeout > 0 {
+		d, dcancel := withClockTimeout(j.ctx, c.clock, timeout)
+		ctx, cancel, expired, stop = d, dcancel, d.expired, d.stop
+	} else {
+		ctx, cancel = context.WithCancel(j.ctx)
+	}
+	ctx = context.WithValue(context.WithValue(ctx, fireTimeKey, fireTime), jobIDKey, j.id)
+	ctx = context.WithValue(ctx, attemptKey, attempt)
+	j.seq++
+	seq := j.seq
+	j.cancels[seq] = cancel
+	j.running++
+	j.workers++
+	c.active++
 	c.wg.Add(1)
 	go func() {
 		defer c.wg.Done()
-		j.run()
+		err := c.execute(ctx, expired, j, fireTime, attempt)
+		c.lock.Lock()
+		delete(j.cancels, seq)
+		stop()
+		cancel()
+		events := []*Event{c.retry(j, fireTime, attempt, err), c.runPending(j)}
+		c.dispatch()
+		c.lock.Unlock()
+		for _, e := range events {
+			if e != nil {
+				c.emit(*e)
+			}
+		}
 	}()
 }
+
+// execute runs an execution of j, waiting for it until it returns or, when it has a timeout,
+// until expired is closed, even if ctx was cancelled by Stop or Remove before
+func (c *Scheduler) execute(ctx context.Context, expired <-chan struct{}, j *job, fireTime time.Time, attempt int) error {
+	c.emit(Event{Type: JobStarted, JobID: j.id, JobName: j.name, FireTime: fireTime, Attempt: attempt})
+	start := c.clock.Now()
+	done := make(chan error, 1)
+	go func() {
+		err := j.run(ctx)
+		//超时后不再等待的执行返回时才释放worker
+		c.lock.Lock()
+		j.workers--
+		c.active--
+		c.dispatch()
+		c.lock.Unlock()
+		done <- err
+	}()
+	var (
+		err      error
+		timedOut bool
+	)
+	select {
+	case err = <-done:
+		timedOut = ctx.Err() == context.DeadlineExceeded
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			//超时后不再等待任务返回
+			timedOut = true
+			err = ctx.Err()
+			break
+		}
+		//被取消后最多等到超时，不理会ctx的任务不能让Stop一直等
+		select {
+		case err = <-done:
+		case <-expired:
+			timedOut = true
+			err = context.DeadlineExceeded
+		}
+	}
+	end := c.clock.Now()
+	c.lock.Lock()
+	j.running--
+	j.record(Execution{FireTime: fireTime, Attempt: attempt, Start: start, End: end, Err: err, TimedOut: timedOut})
+	c.lock.Unlock()
+	e := Event{Type: JobSucceeded, JobID: j.id, JobName: j.name, FireTime: fireTime, Attempt: attempt, Duration: end.Sub(start), Err: err}
+	switch {
+	case timedOut:
+		e.Type = JobTimedOut
+		c.handleError(j, err)
+	case err != nil:
+		e.Type = JobFailed
+		c.handleError(j, err)
+	}
+	c.emit(e)
+	return err
+}
@@ -1,38 +1,184 @@
 package cron
 
 import (
-	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestCron(t *testing.T) {
-	s := New()
+	//2026-07-06是星期一
+	clock := NewFakeClock(time.Date(2026, 7, 6, 8, 0, 0, 0, time.Local))
+	s := New(WithClock(clock))
 	s.Start()
-	id, err := s.AddJob("2/1 * 8-16 * * ?", func() {
-		log.Println("1执行了2/1 * 8-16 * * ?")
-	})
+	defer s.Stop()
+	runs := make(chan string, 10)
+	expect := func(want ...string) {
+		t.Helper()
+		var got []string
+		for range want {
+			select {
+			case name := <-runs:
+				got = append(got, name)
+			case <-time.After(time.Second):
+				t.Fatalf("at %v got %v, want %v", clock.Now(), got, want)
+			}
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("at %v got %v, want %v", clock.Now(), got, want)
+		}
+	}
+	step := func(d time.Duration) {
+		clock.BlockUntil(1)
+		clock.Advance(d)
+	}
+
+	id, err := s.AddJob("2/1 * 8-16 * * ?", func() { runs <- "1" })
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
-	log.Println("id=", id)
-	time.Sleep(time.Second * 2)
-	id, err = s.AddJob("2/1 0,10,20,30,40,50 * ? 7 1-2", func() {
-		log.Println("执行了2/1 0,10,20,30,40,50 * ? 7 1-2")
-	})
+	step(2 * time.Second)
+	expect("1")
+	step(time.Second)
+	expect("1")
+
+	_, err = s.AddJob("2/1 0,10,20,30,40,50 * ? 7 1-2", func() { runs <- "2" })
 	if err != nil {
-		panic(err)
-	}
-	log.Println("id=", id)
-	s.Remove(1)
-	time.Sleep(time.Second * 2)
-	id, err = s.AddJob("* * 8-16 * * ?", func() {
-		log.Println("2执行了* * 8-16 * * ?")
-	})
+		t.Fatal(err)
+	}
+	s.Remove(id)
+	step(time.Second)
+	expect("2")
+
+	_, err = s.AddJob("* * 8-16 * * ?", func() { runs <- "3" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	//8:00:05-8:00:59 两个任务每秒都执行
+	for i := 5; i < 60; i++ {
+		step(time.Second)
+		expect("2", "3")
+	}
+	//8:01:00 只有第3个任务
+	step(time.Second)
+	expect("3")
+
+	<-s.Stop().Done()
+	select {
+	case name := <-runs:
+		t.Errorf("unexpected run of %s", name)
+	default:
+	}
+}
+
+func TestTriggerNext(t *testing.T) {
+	cases := []struct {
+		cron string
+		now  time.Time
+		want time.Time
+	}{
+		{"0 30 9 1 * ?", time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local), time.Date(2026, 2, 1, 9, 30, 0, 0, time.Local)},
+		{"0 0 * 13 * ?", time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 1, 13, 0, 0, 0, 0, time.Local)},
+		{"0 15 10 LW * ?", time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 2, 27, 10, 15, 0, 0, time.Local)},
+		{"0 15 10 ? * 6L", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 3, 28, 10, 15, 0, 0, time.Local)},
+		{"0 0 0 ? 5 0#2", time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 5, 10, 0, 0, 0, 0, time.Local)},
+		{"0 0 12 29 2 ?", time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2028, 2, 29, 12, 0, 0, 0, time.Local)},
+		{"*/5 * * * * ?", time.Date(2026, 1, 1, 23, 59, 57, 0, time.Local), time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)},
+	}
+	for _, c := range cases {
+		if got := MustParse(c.cron).Next(c.now); !got.Equal(c.want) {
+			t.Errorf("%s: Next(%v) = %v, want %v", c.cron, c.now, got, c.want)
+		}
+	}
+
+}
+
+func TestTriggerNextDST(t *testing.T) {
+	//圣保罗2018-11-04夏令时开始，当天没有0点；time.Local只在子进程里改，不影响别的测试
+	if os.Getenv("CRON_TEST_DST") == "" {
+		if _, err := time.LoadLocation("America/Sao_Paulo"); err != nil {
+			t.Skip(err)
+		}
+		cmd := exec.Command(os.Args[0], "-test.run=^TestTriggerNextDST$")
+		cmd.Env = append(os.Environ(), "CRON_TEST_DST=1", "TZ=America/Sao_Paulo")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v\n%s", err, out)
+		}
+		return
+	}
+	loc := time.Local
+	if _, offset := time.Date(2018, 11, 3, 12, 0, 0, 0, loc).Zone(); offset != -3*3600 {
+		t.Fatalf("TZ not applied, offset %d", offset)
+	}
+	cases := []struct {
+		cron string
+		now  time.Time
+		want time.Time
+	}{
+		{"0 0 5 4 11 ?", time.Date(2018, 11, 3, 23, 30, 0, 0, loc), time.Date(2018, 11, 4, 5, 0, 0, 0, loc)},
+		{"0 0 * 5 11 ?", time.Date(2018, 11, 4, 23, 30, 0, 0, loc), time.Date(2018, 11, 5, 0, 0, 0, 0, loc)},
+		{"0 30 0 4 11 ?", time.Date(2018, 11, 3, 12, 0, 0, 0, loc), time.Date(2019, 11, 4, 0, 30, 0, 0, loc)},
+		{"0 0 9 ? 11 0#1", time.Date(2018, 11, 1, 0, 0, 0, 0, loc), time.Date(2018, 11, 4, 9, 0, 0, 0, loc)},
+	}
+	for _, c := range cases {
+		if got := MustParse(c.cron).Next(c.now); !got.Equal(c.want) {
+			t.Errorf("%s: Next(%v) = %v, want %v", c.cron, c.now, got, c.want)
+		}
+	}
+}
+
+func TestAddJobExpressions(t *testing.T) {
+	s := New()
+	id, err := s.AddJobExpressions([]string{"0 0 9 * * ?", "0 0 9,18 ? * MON-FRI"}, func() {})
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
+	}
+	j := s.jobMap[id]
+	now := time.Date(2026, 3, 6, 8, 0, 0, 0, time.Local)
+	want := []time.Time{
+		time.Date(2026, 3, 6, 9, 0, 0, 0, time.Local),
+		time.Date(2026, 3, 6, 18, 0, 0, 0, time.Local),
+		time.Date(2026, 3, 7, 9, 0, 0, 0, time.Local),
+		time.Date(2026, 3, 8, 9, 0, 0, 0, time.Local),
+	}
+	for _, w := range want {
+		now = j.s.Next(now)
+		if !now.Equal(w) {
+			t.Fatalf("next = %v, want %v", now, w)
+		}
+	}
+	if _, err = s.AddJobExpressions([]string{"0 0 9 * * ?", "0 0 25 * * ?"}, func() {}); err == nil {
+		t.Error("invalid expression should be rejected")
+	}
+}
+
+func TestBusinessDay(t *testing.T) {
+	holidays := DatesCalendar(time.Date(2026, 6, 2, 0, 0, 0, 0, time.Local), time.Date(2026, 6, 30, 0, 0, 0, 0, time.Local))
+	cases := []struct {
+		cron string
+		opts []ParseOption
+		now  time.Time
+		want time.Time
+	}{
+		// 2026-06-01 是周一
+		{"0 0 9 3BD * ?", nil, time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 6, 3, 9, 0, 0, 0, time.Local)},
+		{"0 0 9 3BD * ?", []ParseOption{WithHolidays(holidays)}, time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 6, 4, 9, 0, 0, 0, time.Local)},
+		{"0 0 18 -2BD * ?", nil, time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 6, 29, 18, 0, 0, 0, time.Local)},
+		{"0 0 18 -2BD * ?", []ParseOption{WithHolidays(holidays)}, time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 6, 26, 18, 0, 0, 0, time.Local)},
+		{"0 0 18 LW * ?", []ParseOption{WithHolidays(holidays)}, time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 6, 29, 18, 0, 0, 0, time.Local)},
+	}
+	for _, c := range cases {
+		if got := MustParse(c.cron, c.opts...).Next(c.now); !got.Equal(c.want) {
+			t.Errorf("%s: Next(%v) = %v, want %v", c.cron, c.now, got, c.want)
+		}
+	}
+	for _, cron := range []string{"0 0 9 0BD * ?", "0 0 9 24BD * ?", "0 0 9 xBD * ?"} {
+		if _, err := Parse(cron); err == nil {
+			t.Errorf("%s should be rejected", cron)
+		}
 	}
-	log.Println("id=", id)
-	var ch chan struct{}
-	<-ch
 }
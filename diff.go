@@ -0,0 +1,112 @@
+package cron
+
+import (
+	"time"
+)
+
+// FireTimeDiff is a fire time produced by only one of two schedules.
+type FireTimeDiff struct {
+	Time time.Time
+	// OnlyA is true if the time is produced by the first schedule only, false if by the second only.
+	OnlyA bool
+}
+
+// Diff returns the fire times in [from, to) that only one of a and b produces, in time order.
+// At most limit differences are returned, or all of them if limit <= 0.
+func Diff(a, b Schedule, from, to time.Time, limit int) []FireTimeDiff {
+	var diffs []FireTimeDiff
+	ta, tb := nextFrom(a, from), nextFrom(b, from)
+	for limit <= 0 || len(diffs) < limit {
+		inA := !ta.IsZero() && ta.Before(to)
+		inB := !tb.IsZero() && tb.Before(to)
+		switch {
+		case !inA && !inB:
+			return diffs
+		case inA && inB && ta.Equal(tb):
+			ta, tb = a.Next(ta), b.Next(tb)
+		case inA && (!inB || ta.Before(tb)):
+			diffs = append(diffs, FireTimeDiff{Time: ta, OnlyA: true})
+			ta = a.Next(ta)
+		default:
+			diffs = append(diffs, FireTimeDiff{Time: tb})
+			tb = b.Next(tb)
+		}
+	}
+	return diffs
+}
+
+// Equal reports whether a and b produce the same fire times in [from, to).
+func Equal(a, b Schedule, from, to time.Time) bool {
+	return len(Diff(a, b, from, to, 1)) == 0
+}
+
+// CompareExpressions reports whether two cron expressions produce identical schedules. The
+// answer does not depend on a time window: the time of day fields are compared as sets and the
+// matching days are compared over a whole 400 year cycle of the Gregorian calendar. When they
+// differ, up to limit of the first differing fire times after from are returned as well.
+func CompareExpressions(x, y string, from time.Time, limit int, opts ...ParseOption) (equal bool, diffs []FireTimeDiff, err error) {
+	a, err := newTrigger(x, opts...)
+	if err != nil {
+		return false, nil, err
+	}
+	b, err := newTrigger(y, opts...)
+	if err != nil {
+		return false, nil, err
+	}
+	if equalTriggers(a, b) {
+		return true, nil, nil
+	}
+	return false, Diff(a, b, from, from.AddDate(maxYears, 0, 0), limit), nil
+}
+
+// equalTriggers 时分秒的取值集合相同且400年内匹配的日期相同
+func equalTriggers(a, b *trigger) bool {
+	aDays, bDays := a.hasDays(), b.hasDays()
+	if !aDays || !bDays {
+		return aDays == bDays
+	}
+	if (a.ms == nil) != (b.ms == nil) || a.ms != nil && !equalValues(a.ms, b.ms) {
+		return false
+	}
+	if !equalValues(a.sec, b.sec) || !equalValues(a.min, b.min) || !equalValues(a.hour, b.hour) {
+		return false
+	}
+	equal := true
+	a.eachCycleDay(func(d time.Time) bool {
+		equal = (a.mon.match(uint(d.Month())) && a.matchDay(d)) == (b.mon.match(uint(d.Month())) && b.matchDay(d))
+		return equal
+	})
+	return equal
+}
+
+// hasDays reports whether any day of the 400 year cycle matches the trigger
+func (t *trigger) hasDays() bool {
+	found := false
+	t.eachCycleDay(func(d time.Time) bool {
+		found = t.mon.match(uint(d.Month())) && t.matchDay(d)
+		return !found
+	})
+	return found
+}
+
+// eachCycleDay calls f for every day of a 400 year cycle until f returns false
+func (t *trigger) eachCycleDay(f func(d time.Time) bool) {
+	for year := 2000; year < 2400; year++ {
+		for month := 1; month <= 12; month++ {
+			for day := 1; day <= getYearMonthDays(year, month); day++ {
+				if !f(time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func equalValues(a, b *field) bool {
+	for v := ranges[a.name][0]; v <= ranges[a.name][1]; v++ {
+		if a.match(v) != b.match(v) {
+			return false
+		}
+	}
+	return true
+}
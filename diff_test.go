@@ -0,0 +1,46 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareExpressions(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+	cases := []struct {
+		x, y  string
+		equal bool
+	}{
+		{"0 0/5 14 * * ?", "0 0-55/5 14 * * ?", true},
+		{"0 0 10 * * ?", "0 0 10 ? * *", true},
+		{"0 0 12 ? * MON-FRI", "0 0 12 ? * 1,2,3,4,5", true},
+		{"0 0 12 L 2 ?", "0 0 12 28,29 2 ?", false},
+		{"0 0 23 ? * 5L", "0 0 23 ? * FRI", false},
+		{"0 0 0 30 2 ?", "0 0 1 31 2 ?", true},
+	}
+	for _, c := range cases {
+		equal, diffs, err := CompareExpressions(c.x, c.y, from, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if equal != c.equal {
+			t.Errorf("%s vs %s: equal = %v, want %v", c.x, c.y, equal, c.equal)
+		}
+		if !equal && len(diffs) == 0 {
+			t.Errorf("%s vs %s: no differing fire times", c.x, c.y)
+		}
+	}
+	_, diffs, _ := CompareExpressions("0 0 9 ? * MON-FRI", "0 0 9 ? * MON-THU", from, 2)
+	want := []FireTimeDiff{
+		{Time: time.Date(2026, 1, 2, 9, 0, 0, 0, time.Local), OnlyA: true},
+		{Time: time.Date(2026, 1, 9, 9, 0, 0, 0, time.Local), OnlyA: true},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("diffs = %v, want %v", diffs, want)
+	}
+	for i := range want {
+		if !diffs[i].Time.Equal(want[i].Time) || diffs[i].OnlyA != want[i].OnlyA {
+			t.Errorf("diff %d = %v, want %v", i, diffs[i], want[i])
+		}
+	}
+}
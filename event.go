@@ -0,0 +1,112 @@
+package cron
+
+import (
+	"time"
+)
+
+// maxHistory is how many executions are kept per job
+const maxHistory = 100
+
+// EventType is the kind of an Event.
+type EventType int
+
+const (
+	// JobStarted is emitted when an execution starts.
+	JobStarted EventType = iota
+	// JobSucceeded is emitted when an execution returns nil.
+	JobSucceeded
+	// JobFailed is emitted when an execution returns an error or panics.
+	JobFailed
+	// JobSkipped is emitted when a fire time is skipped, e.g. by the overlap policy.
+	JobSkipped
+	// JobDropped is emitted when a fire time is dropped because the queue is full.
+	JobDropped
+	// JobTimedOut is emitted when an execution has run longer than its timeout.
+	JobTimedOut
+	// JobRetrying is emitted when a retry of a failed execution is scheduled.
+	JobRetrying
+	// JobMisfired is emitted for each fire time that passed without running in time.
+	JobMisfired
+)
+
+func (t EventType) String() string {
+	switch t {
+	case JobStarted:
+		return "started"
+	case JobSucceeded:
+		return "succeeded"
+	case JobFailed:
+		return "failed"
+	case JobSkipped:
+		return "skipped"
+	case JobDropped:
+		return "dropped"
+	case JobTimedOut:
+		return "timed out"
+	case JobRetrying:
+		return "retrying"
+	case JobMisfired:
+		return "misfired"
+	}
+	return "unknown"
+}
+
+// Event reports something that happened to a job.
+type Event struct {
+	Type     EventType
+	JobID    uint
+	JobName  string
+	FireTime time.Time
+	// Attempt is 1 for the scheduled execution and greater for retries.
+	Attempt int
+	// Duration is how long the execution ran, set when it has finished.
+	Duration time.Duration
+	Err      error
+}
+
+// EventHandler receives scheduler events. It is called from the goroutines running the jobs,
+// so it must be safe for concurrent use and should return quickly.
+type EventHandler func(e Event)
+
+// WithEventHandler adds a handler receiving the events of all jobs.
+func WithEventHandler(h EventHandler) Option {
+	return func(c *Scheduler) {
+		c.handlers = append(c.handlers, h)
+	}
+}
+
+// Execution is a finished run of a job.
+type Execution struct {
+	FireTime time.Time
+	Attempt  int
+	Start    time.Time
+	End      time.Time
+	Err      error
+	// TimedOut reports whether the execution was abandoned after its timeout.
+	TimedOut bool
+}
+
+// History returns the last executions of the job id, oldest first. At most 100 are kept.
+func (c *Scheduler) History(id uint) []Execution {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	j, ok := c.jobMap[id]
+	if !ok {
+		return nil
+	}
+	return append([]Execution(nil), j.history...)
+}
+
+func (c *Scheduler) emit(e Event) {
+	for _, h := range c.handlers {
+		h(e)
+	}
+}
+
+// record adds an execution to the history of the job, called with c.lock held
+func (j *job) record(e Execution) {
+	if len(j.history) >= maxHistory {
+		j.history = append(j.history[:0], j.history[len(j.history)-maxHistory+1:]...)
+	}
+	j.history = append(j.history, e)
+}
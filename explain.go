@@ -0,0 +1,166 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldMatch describes how one field of a cron expression treats an instant.
+type FieldMatch struct {
+	// Field is the field name: ms, sec, min, hour, day, mon or week.
+	Field string
+	// Expr is the field as written, with H and ~ replaced by their values.
+	Expr string
+	// Value is the value of the instant for the field, e.g. the day of month for day.
+	Value   int
+	Matched bool
+	// Reason explains the result, e.g. "day 15W resolved to 13 in 2026-03 because the 15th is a Sunday, not 14".
+	Reason string
+}
+
+// Explanation reports why an instant does or does not match a cron expression.
+type Explanation struct {
+	Time time.Time
+	// Matched reports whether the expression fires at Time. It requires every field to match
+	// and Time to be on a whole second, or a whole millisecond with WithMilliseconds.
+	Matched bool
+	Fields  []FieldMatch
+	// Next is the first fire time after Time, zero if there is none.
+	Next time.Time
+}
+
+func (e *Explanation) String() string {
+	var b strings.Builder
+	if e.Matched {
+		fmt.Fprintf(&b, "%s matches\n", e.Time.Format("2006-01-02 15:04:05.000 MST"))
+	} else {
+		fmt.Fprintf(&b, "%s does not match\n", e.Time.Format("2006-01-02 15:04:05.000 MST"))
+	}
+	for _, f := range e.Fields {
+		mark := "✓"
+		if !f.Matched {
+			mark = "✗"
+		}
+		fmt.Fprintf(&b, "  %s %s\n", mark, f.Reason)
+	}
+	if !e.Next.IsZero() {
+		fmt.Fprintf(&b, "next: %s\n", e.Next.Format("2006-01-02 15:04:05.000 MST"))
+	}
+	return b.String()
+}
+
+// Explain parses cronExpression and reports, field by field, whether it fires at t and why
+// not, resolving W, L, LW, BD and # for the month of t.
+func Explain(cronExpression string, t time.Time, opts ...ParseOption) (*Explanation, error) {
+	tr, err := newTrigger(cronExpression, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return tr.explain(t), nil
+}
+
+func (t *trigger) explain(at time.Time) *Explanation {
+	local := at.In(time.Local)
+	e := &Explanation{Time: at, Matched: nextFrom(t, at).Equal(at), Next: t.Next(at)}
+	if t.ms != nil {
+		e.Fields = append(e.Fields, explainValue(t.ms, local.Nanosecond()/int(time.Millisecond), ""))
+	}
+	e.Fields = append(e.Fields,
+		explainValue(t.sec, local.Second(), ""),
+		explainValue(t.min, local.Minute(), ""),
+		explainValue(t.hour, local.Hour(), ""),
+	)
+	//星期的L和#会同时算出日，先算星期
+	week := t.explainWeek(local)
+	e.Fields = append(e.Fields,
+		t.explainDay(local),
+		explainValue(t.mon, int(local.Month()), " ("+local.Month().String()+")"),
+		week,
+	)
+	return e
+}
+
+func explainValue(f *field, value int, suffix string) FieldMatch {
+	m := FieldMatch{Field: f.name, Expr: f.expr, Value: value, Matched: f.match(uint(value))}
+	if m.Matched {
+		m.Reason = fmt.Sprintf("%s %d%s matches %s", f.name, value, suffix, f.expr)
+	} else {
+		m.Reason = fmt.Sprintf("%s %d%s does not match %s", f.name, value, suffix, f.expr)
+	}
+	return m
+}
+
+func (t *trigger) explainDay(d time.Time) FieldMatch {
+	year, month, day := d.Date()
+	if t.week.calculate != nil {
+		return FieldMatch{Field: dayField, Expr: t.day.expr, Value: day, Matched: true,
+			Reason: fmt.Sprintf("day %s matches any day, the week field %s picks the date", t.day.expr, t.week.expr)}
+	}
+	if t.day.calculate == nil {
+		return explainValue(t.day, day, "")
+	}
+	m := FieldMatch{Field: dayField, Expr: t.day.expr, Value: day}
+	resolved, ok := t.day.calculate(year, int(month))
+	if !ok {
+		m.Reason = fmt.Sprintf("day %s does not resolve to a day in %04d-%02d", t.day.expr, year, month)
+		return m
+	}
+	m.Matched = day == resolved
+	m.Reason = fmt.Sprintf("day %s resolved to %d in %04d-%02d", t.day.expr, resolved, year, month)
+	//W和LW说明为什么不是原来的那一天
+	nominal := 0
+	if t.day.expr == "LW" {
+		nominal = getYearMonthDays(year, int(month))
+	} else if strings.HasSuffix(t.day.expr, "W") {
+		nominal, _ = strconv.Atoi(strings.TrimSuffix(t.day.expr, "W"))
+	}
+	if nominal > 0 && nominal != resolved && nominal <= getYearMonthDays(year, int(month)) {
+		date := time.Date(year, month, nominal, 0, 0, 0, 0, time.Local)
+		if isWorkDay(date) {
+			m.Reason += fmt.Sprintf(" because the %s is a holiday", ordinal(nominal))
+		} else {
+			m.Reason += fmt.Sprintf(" because the %s is a %s", ordinal(nominal), date.Weekday())
+		}
+	}
+	if !m.Matched {
+		m.Reason += fmt.Sprintf(", not %d", day)
+	}
+	return m
+}
+
+func (t *trigger) explainWeek(d time.Time) FieldMatch {
+	year, month, day := d.Date()
+	if t.week.calculate == nil {
+		return explainValue(t.week, int(d.Weekday()), " ("+d.Weekday().String()+")")
+	}
+	m := FieldMatch{Field: weekField, Expr: t.week.expr, Value: int(d.Weekday())}
+	resolvedDay, ok := t.week.calculate(year, int(month))
+	if !ok {
+		m.Reason = fmt.Sprintf("week %s does not occur in %04d-%02d", t.week.expr, year, month)
+		return m
+	}
+	resolved := time.Date(year, month, resolvedDay, 12, 0, 0, 0, time.Local)
+	m.Matched = day == resolved.Day()
+	m.Reason = fmt.Sprintf("week %s resolved to %s in %04d-%02d", t.week.expr, resolved.Format("2006-01-02 (Mon)"), year, month)
+	if !m.Matched {
+		m.Reason += fmt.Sprintf(", not %s", d.Format("2006-01-02"))
+	}
+	return m
+}
+
+// ordinal 1st 2nd 3rd 4th ...
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
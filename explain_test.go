@@ -0,0 +1,62 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplain(t *testing.T) {
+	cases := []struct {
+		expr    string
+		at      time.Time
+		matched bool
+		field   string
+		reason  string
+	}{
+		{"0 0 9 15W * ?", time.Date(2026, 3, 13, 9, 0, 0, 0, time.Local), true,
+			dayField, "day 15W resolved to 13 in 2026-03 because the 15th is a Sunday"},
+		{"0 0 9 15W * ?", time.Date(2026, 3, 14, 9, 0, 0, 0, time.Local), false,
+			dayField, "day 15W resolved to 13 in 2026-03 because the 15th is a Sunday, not 14"},
+		{"0 0 9 LW * ?", time.Date(2026, 5, 29, 9, 0, 0, 0, time.Local), true,
+			dayField, "day LW resolved to 29 in 2026-05 because the 31st is a Sunday"},
+		{"0 15 10 ? * 6L", time.Date(2026, 3, 21, 10, 15, 0, 0, time.Local), false,
+			weekField, "week 6L resolved to 2026-03-28 (Sat) in 2026-03, not 2026-03-21"},
+		{"0 0 0 ? * 0#2", time.Date(2026, 2, 8, 0, 0, 0, 0, time.Local), true,
+			weekField, "week 0#2 resolved to 2026-02-08 (Sun) in 2026-02"},
+		{"0 0/5 14 * * ?", time.Date(2026, 3, 13, 14, 7, 0, 0, time.Local), false,
+			minField, "min 7 does not match 0/5"},
+		{"0 0 9 ? * MON-FRI", time.Date(2026, 3, 14, 9, 0, 0, 0, time.Local), false,
+			weekField, "week 6 (Saturday) does not match MON-FRI"},
+	}
+	for _, c := range cases {
+		e, err := Explain(c.expr, c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Matched != c.matched {
+			t.Errorf("%s at %s: matched = %v, want %v", c.expr, c.at, e.Matched, c.matched)
+		}
+		found := false
+		for _, f := range e.Fields {
+			if f.Field == c.field {
+				found = true
+				if f.Reason != c.reason {
+					t.Errorf("%s at %s: reason = %q, want %q", c.expr, c.at, f.Reason, c.reason)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s: field %s not explained", c.expr, c.field)
+		}
+	}
+
+	holidays := DatesCalendar(time.Date(2026, 3, 13, 0, 0, 0, 0, time.Local))
+	e, err := Explain("0 0 9 13W * ?", time.Date(2026, 3, 12, 9, 0, 0, 0, time.Local), WithHolidays(holidays))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Matched || !strings.Contains(e.String(), "because the 13th is a holiday") {
+		t.Errorf("holiday explanation:\n%s", e)
+	}
+}
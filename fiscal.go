@@ -0,0 +1,129 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FiscalCalendar describes how a fiscal year is divided into quarters and periods.
+type FiscalCalendar struct {
+	// StartMonth is the month the fiscal year starts in, e.g. time.April. Fiscal years are
+	// named after the calendar year they start in.
+	StartMonth time.Month
+	// Pattern is the number of weeks in the three periods of every quarter, e.g. {4, 4, 5}.
+	// The zero Pattern uses calendar months as periods.
+	Pattern [3]int
+	// WeekStart is the weekday weeks begin on when Pattern is set. The fiscal year starts on
+	// the WeekStart nearest to the first day of StartMonth; the 53rd week of long years is
+	// added to the last period.
+	WeekStart time.Weekday
+}
+
+// FiscalEvent is a boundary of the fiscal calendar.
+type FiscalEvent int
+
+const (
+	PeriodStart FiscalEvent = iota
+	PeriodEnd
+	QuarterStart
+	QuarterEnd
+	YearStart
+	YearEnd
+)
+
+// fiscalSchedule fires at a boundary of every period, quarter or year of a fiscal calendar
+type fiscalSchedule struct {
+	cal         FiscalCalendar
+	event       FiscalEvent
+	businessDay bool
+	clock       time.Duration
+}
+
+// Fiscal returns a schedule firing at clock (HH:MM or HH:MM:SS) on the first or last day of
+// every fiscal period, quarter or year. With businessDay the day is moved to the nearest work
+// day inside the period, the same Monday to Friday rule used by LW.
+//
+//	Fiscal(cal, PeriodEnd, true, "18:00")    last business day of every fiscal period
+//	Fiscal(cal, QuarterStart, false, "00:00") first day of every fiscal quarter
+func Fiscal(cal FiscalCalendar, event FiscalEvent, businessDay bool, clock string) (Schedule, error) {
+	if cal.StartMonth < time.January || cal.StartMonth > time.December {
+		return nil, errors.New(fmt.Sprintf("fiscal start month %d should be in [1,12]", cal.StartMonth))
+	}
+	if cal.Pattern != [3]int{} {
+		if cal.Pattern[0] < 1 || cal.Pattern[1] < 1 || cal.Pattern[2] < 1 || cal.Pattern[0]+cal.Pattern[1]+cal.Pattern[2] != 13 {
+			return nil, errors.New(fmt.Sprintf("fiscal pattern %v should be 13 weeks, e.g. 4-4-5", cal.Pattern))
+		}
+		if cal.WeekStart < time.Sunday || cal.WeekStart > time.Saturday {
+			return nil, errors.New(fmt.Sprintf("fiscal week start %d should be in [0,6]", cal.WeekStart))
+		}
+	}
+	if event < PeriodStart || event > YearEnd {
+		return nil, errors.New(fmt.Sprintf("fiscal event %d is not supported", event))
+	}
+	c, err := parseClock(clock)
+	if err != nil {
+		return nil, err
+	}
+	return &fiscalSchedule{cal: cal, event: event, businessDay: businessDay, clock: c}, nil
+}
+
+func (s *fiscalSchedule) Next(t time.Time) time.Time {
+	for year := t.Year() - 2; year <= t.Year()+maxYears; year++ {
+		for _, day := range s.days(year, t.Location()) {
+			if next := addClock(day, s.clock); next.After(t) {
+				return next
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// days returns the sorted days of the event in the fiscal year
+func (s *fiscalSchedule) days(year int, loc *time.Location) []time.Time {
+	starts := s.cal.periodStarts(year, loc)
+	var days []time.Time
+	for i := 0; i < 12; i++ {
+		first, last := starts[i], starts[i+1].AddDate(0, 0, -1)
+		switch {
+		case s.event == PeriodStart, s.event == QuarterStart && i%3 == 0, s.event == YearStart && i == 0:
+			if s.businessDay {
+				first = getWorkDay(first, 1, isWorkDay)
+			}
+			days = append(days, first)
+		case s.event == PeriodEnd, s.event == QuarterEnd && i%3 == 2, s.event == YearEnd && i == 11:
+			if s.businessDay {
+				last = getWorkDay(last, -1, isWorkDay)
+			}
+			days = append(days, last)
+		}
+	}
+	return days
+}
+
+// periodStarts returns the first days of the 12 periods of the fiscal year and of the next fiscal year
+func (c FiscalCalendar) periodStarts(year int, loc *time.Location) []time.Time {
+	starts := make([]time.Time, 13)
+	if c.Pattern == [3]int{} {
+		for i := range starts {
+			starts[i] = time.Date(year, c.StartMonth+time.Month(i), 1, 0, 0, 0, 0, loc)
+		}
+		return starts
+	}
+	starts[0] = c.yearStart(year, loc)
+	for i := 1; i < 12; i++ {
+		starts[i] = starts[i-1].AddDate(0, 0, 7*c.Pattern[(i-1)%3])
+	}
+	starts[12] = c.yearStart(year+1, loc)
+	return starts
+}
+
+// yearStart 离StartMonth 1号最近的WeekStart
+func (c FiscalCalendar) yearStart(year int, loc *time.Location) time.Time {
+	first := time.Date(year, c.StartMonth, 1, 0, 0, 0, 0, loc)
+	diff := (int(c.WeekStart) - int(first.Weekday()) + 7) % 7
+	if diff > 3 {
+		diff -= 7
+	}
+	return first.AddDate(0, 0, diff)
+}
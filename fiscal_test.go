@@ -0,0 +1,38 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFiscal(t *testing.T) {
+	retail := FiscalCalendar{StartMonth: time.April, Pattern: [3]int{4, 4, 5}, WeekStart: time.Monday}
+	months := FiscalCalendar{StartMonth: time.April}
+	cases := []struct {
+		cal         FiscalCalendar
+		event       FiscalEvent
+		businessDay bool
+		from        time.Time
+		want        time.Time
+	}{
+		{retail, YearStart, false, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC)},
+		{retail, PeriodEnd, true, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 24, 0, 0, 0, 0, time.UTC)},
+		{retail, QuarterStart, false, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 6, 29, 0, 0, 0, 0, time.UTC)},
+		{retail, YearEnd, true, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 3, 26, 0, 0, 0, 0, time.UTC)},
+		{months, QuarterStart, false, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{months, PeriodEnd, true, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)},
+		{months, YearEnd, false, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		s, err := Fiscal(c.cal, c.event, c.businessDay, "00:00")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Next(c.from); !got.Equal(c.want) {
+			t.Errorf("event %d: Next(%v) = %v, want %v", c.event, c.from, got, c.want)
+		}
+	}
+	if _, err := Fiscal(FiscalCalendar{StartMonth: time.April, Pattern: [3]int{4, 4, 4}}, PeriodEnd, false, "00:00"); err == nil {
+		t.Error("pattern 4-4-4 should be rejected")
+	}
+}
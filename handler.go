@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"fmt"
+	"os"
+)
+
+// PanicError is the error of an execution that panicked.
+type PanicError struct {
+	// Value is the value recovered from the panic.
+	Value interface{}
+	// Stack is the stack of the goroutine that panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// PanicHandler handles a panic of the job id, with the recovered value and the stack.
+type PanicHandler func(id uint, name string, recovered interface{}, stack []byte)
+
+// ErrorHandler handles an error returned by the job id.
+type ErrorHandler func(id uint, name string, err error)
+
+// WithPanicHandler replaces the default handling of job panics, which prints the stack to stderr.
+func WithPanicHandler(h PanicHandler) Option {
+	return func(c *Scheduler) {
+		c.panicHandler = h
+	}
+}
+
+// WithErrorHandler sets a handler for the errors returned by jobs. Panics go to the panic handler.
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(c *Scheduler) {
+		c.errorHandler = h
+	}
+}
+
+// defaultPanicHandler 打印到标准错误
+func defaultPanicHandler(id uint, name string, recovered interface{}, stack []byte) {
+	fmt.Fprintf(os.Stderr, "cron: job %d %s panicked: %v\n%s", id, name, recovered, stack)
+}
+
+// handleError passes the error of an execution of j to the panic or error handler
+func (c *Scheduler) handleError(j *job, err error) {
+	if p, ok := err.(*PanicError); ok {
+		if c.panicHandler != nil {
+			c.panicHandler(j.id, j.name, p.Value, p.Stack)
+		}
+		return
+	}
+	if c.errorHandler != nil {
+		c.errorHandler(j.id, j.name, err)
+	}
+}
@@ -0,0 +1,55 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlers(t *testing.T) {
+	type failure struct {
+		id    uint
+		name  string
+		value interface{}
+		stack string
+		err   error
+	}
+	panics := make(chan failure, 10)
+	errs := make(chan failure, 10)
+	s := New(
+		WithPanicHandler(func(id uint, name string, recovered interface{}, stack []byte) {
+			panics <- failure{id: id, name: name, value: recovered, stack: string(stack)}
+		}),
+		WithErrorHandler(func(id uint, name string, err error) {
+			errs <- failure{id: id, name: name, err: err}
+		}),
+	)
+	every, _ := Every(10 * time.Millisecond)
+	boom := s.AddSchedule(every, func() {
+		panic("boom")
+	}, WithName("boom"))
+	errFailure := errors.New("failure")
+	failing := s.AddScheduleJob(every, FuncJob(func(ctx context.Context) error {
+		return errFailure
+	}), WithName("failing"))
+	s.Start()
+	p := <-panics
+	e := <-errs
+	<-s.Stop().Done()
+
+	if p.id != boom || p.name != "boom" || p.value != "boom" || !strings.Contains(p.stack, "TestHandlers") {
+		t.Errorf("panic handler got %d %s %v\n%s", p.id, p.name, p.value, p.stack)
+	}
+	if e.id != failing || e.name != "failing" || e.err != errFailure {
+		t.Errorf("error handler got %d %s %v", e.id, e.name, e.err)
+	}
+	history := s.History(boom)
+	if len(history) == 0 {
+		t.Fatal("no history")
+	}
+	if pe, ok := history[0].Err.(*PanicError); !ok || pe.Value != "boom" {
+		t.Errorf("history error = %v", history[0].Err)
+	}
+}
@@ -0,0 +1,250 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxICSEvents limits how many fire times of one job are exported as separate events
+const maxICSEvents = 1000
+
+const icsTimeFormat = "20060102T150405Z"
+
+// rruler is implemented by schedules that can be written as an equivalent iCalendar recurrence rule
+type rruler interface {
+	// rrule returns DTSTART, the RRULE value and EXDATE values, ok is false if there is no equivalent rule
+	rrule() (dtstart time.Time, rule string, exdates []time.Time, ok bool)
+}
+
+// ExportICS writes the upcoming fire times in [from, to) of all jobs as an iCalendar (.ics)
+// document. Jobs whose schedule has an equivalent RRULE are written as one recurring event
+// starting at their first fire time in the range, with an UNTIL before to and a VTIMEZONE
+// covering the DST transitions up to to if it has a time zone.
+func (c *Scheduler) ExportICS(w io.Writer, from, to time.Time) error {
+	c.lock.Lock()
+	jobs := make([]*job, 0, len(c.jobMap))
+	for _, j := range c.jobMap {
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].id < jobs[j].id
+	})
+	//运行循环也在计算触发时间，持有锁时生成
+	ics := c.formatICS(jobs, from, to)
+	c.lock.Unlock()
+	_, err := io.WriteString(w, ics)
+	return err
+}
+
+// ExportJobICS is like ExportICS for the single job id.
+func (c *Scheduler) ExportJobICS(w io.Writer, id uint, from, to time.Time) error {
+	c.lock.Lock()
+	j, ok := c.jobMap[id]
+	if !ok {
+		c.lock.Unlock()
+		return errors.New(fmt.Sprintf("job %d does not exist", id))
+	}
+	ics := c.formatICS([]*job{j}, from, to)
+	c.lock.Unlock()
+	_, err := io.WriteString(w, ics)
+	return err
+}
+
+// formatICS returns the iCalendar document of the jobs, called with c.lock held
+func (c *Scheduler) formatICS(jobs []*job, from, to time.Time) string {
+	stamp := c.clock.Now().UTC().Format(icsTimeFormat)
+	var events strings.Builder
+	//TZID对应的VTIMEZONE从最早的DTSTART开始
+	zones := make(map[string]time.Time)
+	var zoneNames []string
+	for _, j := range jobs {
+		summary := j.name
+		if summary == "" {
+			summary = fmt.Sprintf("job %d", j.id)
+		}
+		event := func(uid string, props ...string) {
+			foldICS(&events, "BEGIN:VEVENT")
+			foldICS(&events, "UID:"+uid)
+			foldICS(&events, "DTSTAMP:"+stamp)
+			for _, prop := range props {
+				foldICS(&events, prop)
+			}
+			foldICS(&events, "SUMMARY:"+escapeICSText(summary))
+			if desc := describeSchedule(j.s); desc != "" {
+				foldICS(&events, "DESCRIPTION:"+escapeICSText(desc))
+			}
+			foldICS(&events, "END:VEVENT")
+		}
+		if r, ok := j.s.(rruler); ok && len(j.calendars) == 0 {
+			dtstart, rule, exdates, ok := r.rrule()
+			if ok {
+				rule, ok = boundRule(rule, dtstart.Location(), to)
+			}
+			//本地时区没有TZID名称，夏令时下无法等价表示
+			if loc := dtstart.Location().String(); ok && loc != "Local" {
+				//从from之后的第一次触发开始，到to之前结束
+				first := j.s.Next(from.Add(-time.Nanosecond))
+				if first.IsZero() || !first.Before(to) {
+					continue
+				}
+				dtstart = first.In(dtstart.Location())
+				props := []string{"DTSTART:" + dtstart.UTC().Format(icsTimeFormat), "RRULE:" + rule}
+				if loc != "UTC" {
+					props[0] = "DTSTART;TZID=" + loc + ":" + dtstart.Format("20060102T150405")
+					if start, ok := zones[loc]; !ok {
+						zoneNames = append(zoneNames, loc)
+						zones[loc] = dtstart
+					} else if dtstart.Before(start) {
+						zones[loc] = dtstart
+					}
+				}
+				for _, exdate := range exdates {
+					if !exdate.Before(dtstart) && exdate.Before(to) {
+						props = append(props, "EXDATE:"+exdate.UTC().Format(icsTimeFormat))
+					}
+				}
+				event(fmt.Sprintf("job-%d@cron", j.id), props...)
+				continue
+			}
+		}
+		next := j.nextIncluded(from.Add(-time.Nanosecond))
+		for i := 0; i < maxICSEvents && !next.IsZero() && next.Before(to); i++ {
+			start := next.UTC().Format(icsTimeFormat)
+			event(fmt.Sprintf("job-%d-%s@cron", j.id, start), "DTSTART:"+start)
+			next = j.nextIncluded(*next)
+		}
+	}
+	var b strings.Builder
+	foldICS(&b, "BEGIN:VCALENDAR")
+	foldICS(&b, "VERSION:2.0")
+	foldICS(&b, "PRODID:-//simonybfq//cron//EN")
+	foldICS(&b, "CALSCALE:GREGORIAN")
+	for _, name := range zoneNames {
+		start := zones[name]
+		writeVTimezone(&b, start.Location(), start, from, to)
+	}
+	b.WriteString(events.String())
+	foldICS(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// boundRule returns rule with an UNTIL before to, keeping an earlier UNTIL of its own. ok is false
+// if rule has a COUNT, which counts from its original DTSTART.
+func boundRule(rule string, loc *time.Location, to time.Time) (string, bool) {
+	until := to.Add(-time.Second).UTC()
+	var parts []string
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		switch strings.ToUpper(kv[0]) {
+		case "COUNT":
+			return "", false
+		case "UNTIL":
+			if len(kv) == 2 {
+				if t, dateOnly, err := parseICalTime(kv[1], loc); err == nil {
+					if dateOnly {
+						t = t.AddDate(0, 0, 1).Add(-time.Second)
+					}
+					if t.Before(until) {
+						until = t.UTC()
+					}
+				}
+			}
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(append(parts, "UNTIL="+until.Format(icsTimeFormat)), ";"), true
+}
+
+// foldICS writes a content line, folded after 75 bytes
+func foldICS(b *strings.Builder, s string) {
+	for len(s) > 75 {
+		cut := 75
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(s[:cut] + "\r\n")
+		s = " " + s[cut:]
+	}
+	b.WriteString(s + "\r\n")
+}
+
+// writeVTimezone writes the VTIMEZONE of loc: the offset at from applies since start, followed
+// by the transitions before to
+func writeVTimezone(b *strings.Builder, loc *time.Location, start, from, to time.Time) {
+	foldICS(b, "BEGIN:VTIMEZONE")
+	foldICS(b, "TZID:"+loc.String())
+	t := from.In(loc)
+	if start.After(t) {
+		start = t
+	}
+	_, offset := t.Zone()
+	writeObservance(b, start, offset, offset, t)
+	for t.Before(to) {
+		next := t.Add(12 * time.Hour)
+		if _, o := next.Zone(); o != offset {
+			//二分查找跳变的时刻
+			lo, hi := t, next
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.Zone(); o == offset {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			writeObservance(b, hi, offset, o, hi)
+			offset, next = o, hi
+		}
+		t = next
+	}
+	foldICS(b, "END:VTIMEZONE")
+}
+
+// writeObservance writes a STANDARD or DAYLIGHT component starting at onset, named after the zone in effect at zone
+func writeObservance(b *strings.Builder, onset time.Time, offsetFrom, offsetTo int, zone time.Time) {
+	kind := "STANDARD"
+	if zone.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	name, _ := zone.Zone()
+	foldICS(b, "BEGIN:"+kind)
+	//DTSTART是跳变前的本地时间
+	foldICS(b, "DTSTART:"+onset.UTC().Add(time.Duration(offsetFrom)*time.Second).Format("20060102T150405"))
+	foldICS(b, "TZOFFSETFROM:"+formatICSOffset(offsetFrom))
+	foldICS(b, "TZOFFSETTO:"+formatICSOffset(offsetTo))
+	foldICS(b, "TZNAME:"+escapeICSText(name))
+	foldICS(b, "END:"+kind)
+}
+
+// formatICSOffset formats a UTC offset in seconds as +hhmm[ss]
+func formatICSOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+	if offset%60 != 0 {
+		s += fmt.Sprintf("%02d", offset%60)
+	}
+	return s
+}
+
+// describeSchedule returns the textual form of s if it has one
+func describeSchedule(s Schedule) string {
+	switch v := s.(type) {
+	case *trigger:
+		return v.cron
+	case *rruleSchedule:
+		return v.rule
+	}
+	return ""
+}
+
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,113 @@
+package cron
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExportICS(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	s := New(WithClock(NewFakeClock(time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC))))
+	rule, err := ParseRRule("DTSTART:20260105T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddSchedule(rule, func() {}, WithName("weekly report"))
+	id, err := s.AddJob("0 0 2 * * ?", func() {}, WithName("nightly, heavy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//COUNT从原来的DTSTART算起，只能逐次导出
+	counted, err := ParseRRule("DTSTART:20260105T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=TU;COUNT=9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddSchedule(counted, func() {})
+	var b strings.Builder
+	to := from.AddDate(0, 0, 3)
+	if err = s.ExportICS(&b, from, to); err != nil {
+		t.Fatal(err)
+	}
+	ics := b.String()
+	//RRULE从from之后的第一次开始，到to之前结束
+	until := to.Add(-time.Second).UTC().Format("20060102T150405Z")
+	for _, want := range []string{"BEGIN:VCALENDAR\r\n", "DTSTAMP:20260201T080000Z\r\n", "DTSTART:20260302T090000Z\r\nRRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=" + until + "\r\n",
+		"SUMMARY:nightly\\, heavy\r\n", "UID:job-3-20260303T090000Z@cron\r\n", "END:VCALENDAR\r\n"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("ics does not contain %q:\n%s", want, ics)
+		}
+	}
+	if strings.Contains(ics, "VTIMEZONE") {
+		t.Errorf("ics in UTC should not have a VTIMEZONE:\n%s", ics)
+	}
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 5 {
+		t.Errorf("ics has %d events, want 5", n)
+	}
+	b.Reset()
+	if err = s.ExportJobICS(&b, id, from, from.AddDate(0, 0, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(b.String(), "BEGIN:VEVENT"); n != 1 {
+		t.Errorf("job ics has %d events, want 1", n)
+	}
+}
+
+func TestExportICSTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	s := New()
+	//2026-03-01是星期日，不在选中的星期几里
+	weekly, _ := EveryWeeks(time.Date(2026, 3, 1, 9, 0, 0, 0, newYork), 2, time.Monday)
+	id := s.AddSchedule(weekly, func() {})
+	var b strings.Builder
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, newYork)
+	if err = s.ExportJobICS(&b, id, from, from.AddDate(0, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	ics := b.String()
+	for _, want := range []string{
+		"BEGIN:VTIMEZONE\r\nTZID:America/New_York\r\n",
+		"BEGIN:DAYLIGHT\r\nDTSTART:20260308T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\nEND:DAYLIGHT\r\n",
+		"DTSTART;TZID=America/New_York:20260302T090000\r\n",
+		"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO;WKST=SU;UNTIL=20260401T035959Z\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("ics does not contain %q:\n%s", want, ics)
+		}
+	}
+
+	//范围从中间开始时DTSTART是范围内的第一次，隔周的节奏不变
+	b.Reset()
+	if err = s.ExportJobICS(&b, id, from.AddDate(0, 0, 9), from.AddDate(0, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "DTSTART;TZID=America/New_York:20260316T090000\r\n"; !strings.Contains(b.String(), want) {
+		t.Errorf("ics does not contain %q:\n%s", want, b.String())
+	}
+}
+
+func TestExportICSConcurrent(t *testing.T) {
+	s := New()
+	if _, err := s.AddJob("0 15 10 LW * ?", func() {}); err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	defer s.Stop()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var b strings.Builder
+			if err := s.ExportICS(&b, from, from.AddDate(1, 0, 0)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,48 @@
+package cron
+
+import (
+	"context"
+	"time"
+)
+
+// Job is the work run by the Scheduler at each fire time. The context is cancelled when the
+// Scheduler stops or the job is removed, and carries the fire time and the job id, see
+// FireTime and JobID. A returned error is reported to event handlers and kept in the history.
+type Job interface {
+	Run(ctx context.Context) error
+}
+
+// FuncJob adapts a function to a Job.
+type FuncJob func(ctx context.Context) error
+
+func (f FuncJob) Run(ctx context.Context) error {
+	return f(ctx)
+}
+
+// plainJob 没有context和返回值的任务
+type plainJob func()
+
+func (f plainJob) Run(ctx context.Context) error {
+	f()
+	return nil
+}
+
+type contextKey int
+
+const (
+	fireTimeKey contextKey = iota
+	jobIDKey
+	attemptKey
+)
+
+// FireTime returns the scheduled fire time of the execution running with ctx.
+func FireTime(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(fireTimeKey).(time.Time)
+	return t, ok
+}
+
+// JobID returns the id of the job running with ctx.
+func JobID(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(jobIDKey).(uint)
+	return id, ok
+}
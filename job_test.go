@@ -0,0 +1,87 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJobContext(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+	s := New(WithEventHandler(func(e Event) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}))
+	every, _ := Every(10 * time.Millisecond)
+	failure := errors.New("failure")
+	type info struct {
+		id       uint
+		fireTime time.Time
+	}
+	got := make(chan info, 1)
+	id := s.AddScheduleJob(every, FuncJob(func(ctx context.Context) error {
+		id, _ := JobID(ctx)
+		fireTime, _ := FireTime(ctx)
+		select {
+		case got <- info{id, fireTime}:
+		default:
+		}
+		return failure
+	}), WithName("failing"))
+	s.Start()
+	first := <-got
+	if first.id != id || first.fireTime.IsZero() || first.fireTime.UnixNano()%int64(10*time.Millisecond) != 0 {
+		t.Errorf("context id = %d fire time = %v", first.id, first.fireTime)
+	}
+	time.Sleep(30 * time.Millisecond)
+	<-s.Stop().Done()
+
+	history := s.History(id)
+	if len(history) == 0 || history[0].Err != failure {
+		t.Fatalf("history = %v", history)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) < 2 || events[0].Type != JobStarted || events[1].Type != JobFailed || events[1].Err != failure || events[1].JobName != "failing" {
+		t.Errorf("events = %v", events)
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	s := New()
+	every, _ := Every(10 * time.Millisecond)
+	started := make(chan struct{}, 10)
+	id := s.AddScheduleJob(every, FuncJob(func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	s.Start()
+	<-started
+	s.Remove(id)
+	select {
+	case <-s.Stop().Done():
+	case <-time.After(time.Second):
+		t.Fatal("Remove did not cancel the running job")
+	}
+
+	s = New()
+	s.AddScheduleJob(every, FuncJob(func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil
+	}))
+	s.Start()
+	<-started
+	select {
+	case <-s.Stop().Done():
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not cancel the running job")
+	}
+}
@@ -0,0 +1,182 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	lunarMinYear = 1900
+	lunarMaxYear = 2100
+)
+
+// lunarInfo 农历1900-2100年的数据
+//
+// 0-3位: 闰月月份，0表示无闰月
+// 4-15位: 1-12月的大小，第15位为1月，1为大月30天，0为小月29天
+// 16位: 闰月的大小，1为大月30天
+var lunarInfo = [...]uint32{
+	0x04bd8, 0x04ae0, 0x0a570, 0x054d5, 0x0d260, 0x0d950, 0x16554, 0x056a0, 0x09ad0, 0x055d2, //1900-1909
+	0x04ae0, 0x0a5b6, 0x0a4d0, 0x0d250, 0x1d255, 0x0b540, 0x0d6a0, 0x0ada2, 0x095b0, 0x14977, //1910-1919
+	0x04970, 0x0a4b0, 0x0b4b5, 0x06a50, 0x06d40, 0x1ab54, 0x02b60, 0x09570, 0x052f2, 0x04970, //1920-1929
+	0x06566, 0x0d4a0, 0x0ea50, 0x16a95, 0x05ad0, 0x02b60, 0x186e3, 0x092e0, 0x1c8d7, 0x0c950, //1930-1939
+	0x0d4a0, 0x1d8a6, 0x0b550, 0x056a0, 0x1a5b4, 0x025d0, 0x092d0, 0x0d2b2, 0x0a950, 0x0b557, //1940-1949
+	0x06ca0, 0x0b550, 0x15355, 0x04da0, 0x0a5b0, 0x14573, 0x052b0, 0x0a9a8, 0x0e950, 0x06aa0, //1950-1959
+	0x0aea6, 0x0ab50, 0x04b60, 0x0aae4, 0x0a570, 0x05260, 0x0f263, 0x0d950, 0x05b57, 0x056a0, //1960-1969
+	0x096d0, 0x04dd5, 0x04ad0, 0x0a4d0, 0x0d4d4, 0x0d250, 0x0d558, 0x0b540, 0x0b6a0, 0x195a6, //1970-1979
+	0x095b0, 0x049b0, 0x0a974, 0x0a4b0, 0x0b27a, 0x06a50, 0x06d40, 0x0af46, 0x0ab60, 0x09570, //1980-1989
+	0x04af5, 0x04970, 0x064b0, 0x074a3, 0x0ea50, 0x06b58, 0x05ac0, 0x0ab60, 0x096d5, 0x092e0, //1990-1999
+	0x0c960, 0x0d954, 0x0d4a0, 0x0da50, 0x07552, 0x056a0, 0x0abb7, 0x025d0, 0x092d0, 0x0cab5, //2000-2009
+	0x0a950, 0x0b4a0, 0x0baa4, 0x0ad50, 0x055d9, 0x04ba0, 0x0a5b0, 0x15176, 0x052b0, 0x0a930, //2010-2019
+	0x07954, 0x06aa0, 0x0ad50, 0x05b52, 0x04b60, 0x0a6e6, 0x0a4e0, 0x0d260, 0x0ea65, 0x0d530, //2020-2029
+	0x05aa0, 0x076a3, 0x096d0, 0x04afb, 0x04ad0, 0x0a4d0, 0x1d0b6, 0x0d250, 0x0d520, 0x0dd45, //2030-2039
+	0x0b5a0, 0x056d0, 0x055b2, 0x049b0, 0x0a577, 0x0a4b0, 0x0aa50, 0x1b255, 0x06d20, 0x0ada0, //2040-2049
+	0x14b63, 0x09370, 0x049f8, 0x04970, 0x064b0, 0x168a6, 0x0ea50, 0x06b20, 0x1a6c4, 0x0aae0, //2050-2059
+	0x092e0, 0x0d2e3, 0x0c960, 0x0d557, 0x0d4a0, 0x0da50, 0x05d55, 0x056a0, 0x0a6d0, 0x055d4, //2060-2069
+	0x052d0, 0x0a9b8, 0x0a950, 0x0b4a0, 0x0b6a6, 0x0ad50, 0x055a0, 0x0aba4, 0x0a5b0, 0x052b0, //2070-2079
+	0x0b273, 0x06930, 0x07337, 0x06aa0, 0x0ad50, 0x14b55, 0x04b60, 0x0a570, 0x054e4, 0x0d160, //2080-2089
+	0x0e968, 0x0d520, 0x0daa0, 0x16aa6, 0x056d0, 0x04ae0, 0x0a9d4, 0x0a2d0, 0x0d150, 0x0f252, //2090-2099
+	0x0d520, //2100
+}
+
+// lunarBase 农历1900年正月初一对应的公历日期
+var lunarBase = time.Date(1900, 1, 31, 0, 0, 0, 0, time.UTC)
+
+// lunarSchedule fires on a day of the Chinese lunar calendar
+type lunarSchedule struct {
+	month int
+	day   int
+	clock time.Duration
+}
+
+// Lunar returns a schedule firing at clock (HH:MM or HH:MM:SS) on day of the lunar month.
+// month is 1-12, or 0 for every lunar month including leap months; a specific month never
+// matches its leap month. day is 1-30, or -1 for the last day of the month (e.g. 除夕 is
+// Lunar(12, -1, "00:00")). Months without the day are skipped. Dates from 1900 to 2100 are supported.
+//
+//	Lunar(1, 1, "09:00")   春节
+//	Lunar(5, 5, "09:00")   端午
+//	Lunar(8, 15, "09:00")  中秋
+//	Lunar(0, 1, "09:00")   每月初一
+func Lunar(month, day int, clock string) (Schedule, error) {
+	if month < 0 || month > 12 {
+		return nil, errors.New(fmt.Sprintf("lunar month %d should be in [0,12]", month))
+	}
+	if day != -1 && (day < 1 || day > 30) {
+		return nil, errors.New(fmt.Sprintf("lunar day %d should be in [1,30] or -1", day))
+	}
+	c, err := parseClock(clock)
+	if err != nil {
+		return nil, err
+	}
+	return &lunarSchedule{month: month, day: day, clock: c}, nil
+}
+
+func (s *lunarSchedule) Next(t time.Time) time.Time {
+	year, month, _, leap, ok := solarToLunar(t)
+	if !ok {
+		return time.Time{}
+	}
+	for year <= lunarMaxYear {
+		if s.month == 0 || (s.month == month && !leap) {
+			days := lunarMonthDays(year, month, leap)
+			day := s.day
+			if day < 0 {
+				day = days + 1 + day
+			}
+			if day <= days {
+				next := addClock(lunarToSolar(year, month, day, leap, t.Location()), s.clock)
+				if next.After(t) {
+					return next
+				}
+			}
+		}
+		//下一个农历月，闰月紧跟在同名月份之后
+		if !leap && lunarLeapMonth(year) == month {
+			leap = true
+		} else {
+			leap = false
+			if month++; month > 12 {
+				month = 1
+				year++
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// lunarLeapMonth 闰月月份，没有闰月返回0
+func lunarLeapMonth(year int) int {
+	return int(lunarInfo[year-lunarMinYear] & 0xf)
+}
+func lunarMonthDays(year, month int, leap bool) int {
+	info := lunarInfo[year-lunarMinYear]
+	if leap {
+		if info&0x10000 != 0 {
+			return 30
+		}
+		return 29
+	}
+	if info&(0x10000>>uint(month)) != 0 {
+		return 30
+	}
+	return 29
+}
+func lunarYearDays(year int) int {
+	days := 0
+	for m := 1; m <= 12; m++ {
+		days += lunarMonthDays(year, m, false)
+	}
+	if lunarLeapMonth(year) > 0 {
+		days += lunarMonthDays(year, lunarLeapMonth(year), true)
+	}
+	return days
+}
+
+// solarToLunar converts the date of t to a lunar date, ok is false outside 1900-2100
+func solarToLunar(t time.Time) (year, month, day int, leap, ok bool) {
+	offset := civilDays(lunarBase, t)
+	if offset < 0 {
+		return 0, 0, 0, false, false
+	}
+	for year = lunarMinYear; year <= lunarMaxYear && offset >= lunarYearDays(year); year++ {
+		offset -= lunarYearDays(year)
+	}
+	if year > lunarMaxYear {
+		return 0, 0, 0, false, false
+	}
+	for month = 1; ; {
+		days := lunarMonthDays(year, month, leap)
+		if offset < days {
+			return year, month, offset + 1, leap, true
+		}
+		offset -= days
+		if !leap && lunarLeapMonth(year) == month {
+			leap = true
+		} else {
+			leap = false
+			month++
+		}
+	}
+}
+
+// lunarToSolar returns midnight in loc of the solar date of the lunar date
+func lunarToSolar(year, month, day int, leap bool, loc *time.Location) time.Time {
+	offset := day - 1
+	for y := lunarMinYear; y < year; y++ {
+		offset += lunarYearDays(y)
+	}
+	leapMonth := lunarLeapMonth(year)
+	for m := 1; m < month; m++ {
+		offset += lunarMonthDays(year, m, false)
+		if m == leapMonth {
+			offset += lunarMonthDays(year, m, true)
+		}
+	}
+	if leap {
+		offset += lunarMonthDays(year, month, false)
+	}
+	d := lunarBase.AddDate(0, 0, offset)
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
+}
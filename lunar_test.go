@@ -0,0 +1,36 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLunar(t *testing.T) {
+	cases := []struct {
+		month, day int
+		from       time.Time
+		want       time.Time
+	}{
+		{1, 1, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 10, 9, 0, 0, 0, time.UTC)},
+		{1, 1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 29, 9, 0, 0, 0, time.UTC)},
+		{1, 1, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 17, 9, 0, 0, 0, time.UTC)},
+		{1, 1, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2000, 2, 5, 9, 0, 0, 0, time.UTC)},
+		{5, 5, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 5, 31, 9, 0, 0, 0, time.UTC)},
+		{8, 15, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 10, 6, 9, 0, 0, 0, time.UTC)},
+		{8, 15, time.Date(2024, 9, 17, 9, 0, 0, 0, time.UTC), time.Date(2025, 10, 6, 9, 0, 0, 0, time.UTC)},
+		// 2025年闰六月，闰六月初一是2025-07-25
+		{0, 1, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 7, 25, 9, 0, 0, 0, time.UTC)},
+		{7, 1, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 8, 23, 9, 0, 0, 0, time.UTC)},
+		// 除夕
+		{12, -1, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 16, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		s, err := Lunar(c.month, c.day, "09:00")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Next(c.from); !got.Equal(c.want) {
+			t.Errorf("Lunar(%d, %d).Next(%v) = %v, want %v", c.month, c.day, c.from, got, c.want)
+		}
+	}
+}
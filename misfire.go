@@ -0,0 +1,89 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultMisfireThreshold is how late a fire time may run before it counts as misfired
+	defaultMisfireThreshold = time.Second
+	// maxMisfires limits how many missed fire times of a job are handled at once
+	maxMisfires = 1000
+)
+
+// MisfirePolicy decides what happens to fire times that have passed without running, e.g.
+// while the process was suspended or the scheduler was stopped.
+type MisfirePolicy int
+
+const (
+	// MisfireFireOnce runs the job once now for all the missed fire times, with the latest as
+	// its fire time.
+	MisfireFireOnce MisfirePolicy = iota
+	// MisfireFireAll runs the job once for every missed fire time, oldest first.
+	MisfireFireAll
+	// MisfireSkip skips the missed fire times and waits for the next one. As fire times within
+	// the threshold are not missed, with WithMisfireThreshold it is Quartz's "ignore if later
+	// than the threshold": late fire times are dropped, the others run.
+	MisfireSkip
+)
+
+// WithMisfire sets the misfire policy of the job, MisfireFireOnce by default.
+// Each misfired fire time is reported as a JobMisfired event. At most 1000 missed fire times
+// are handled at once; the rest are dropped and reported in one JobMisfired event with an error.
+func WithMisfire(p MisfirePolicy) JobOption {
+	return func(j *job) {
+		j.misfire = p
+	}
+}
+
+// WithMisfireThreshold sets how late a fire time of the job may run and still count as on time,
+// 1s by default. Later fire times are misfired and handled by the misfire policy, the ones on
+// time always run.
+func WithMisfireThreshold(d time.Duration) JobOption {
+	return func(j *job) {
+		j.misfireThreshold = d
+	}
+}
+
+// fireDue runs the fire times of j up to now, applying its misfire policy to the late ones,
+// and moves j to its next fire time after now. Called with c.lock held, it returns the events
+// to emit after c.lock is released.
+func (c *Scheduler) fireDue(j *job, now time.Time) (events []*Event) {
+	threshold := j.misfireThreshold
+	if threshold <= 0 {
+		threshold = defaultMisfireThreshold
+	}
+	var late, onTime []time.Time
+	t := *j.nextTime
+	for ; !t.IsZero() && !t.After(now) && len(late)+len(onTime) < maxMisfires; t = *j.nextIncluded(t) {
+		if now.Sub(t) > threshold {
+			late = append(late, t)
+			events = append(events, &Event{Type: JobMisfired, JobID: j.id, JobName: j.name, FireTime: t})
+		} else {
+			onTime = append(onTime, t)
+		}
+	}
+	if !t.IsZero() && !t.After(now) {
+		//超过上限的不再逐个处理，报告一次
+		err := errors.New(fmt.Sprintf("more than %d fire times missed, dropped the ones from %s on", maxMisfires, t.Format(time.RFC3339)))
+		events = append(events, &Event{Type: JobMisfired, JobID: j.id, JobName: j.name, FireTime: t, Err: err})
+	}
+	//错过的按策略处理，没错过的都执行
+	var fires []time.Time
+	switch {
+	case len(late) == 0 || j.misfire == MisfireSkip:
+	case j.misfire == MisfireFireAll:
+		fires = late
+	default:
+		fires = late[len(late)-1:]
+	}
+	for _, t := range append(fires, onTime...) {
+		if e := c.fire(j, t, 1, true); e != nil {
+			events = append(events, e)
+		}
+	}
+	j.next(now)
+	return events
+}
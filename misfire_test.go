@@ -0,0 +1,92 @@
+package cron
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestMisfire(t *testing.T) {
+	minutely, _ := Every(time.Minute)
+	//停止期间错过7:58、7:59、8:00，8:00:45重新启动
+	at := func(min int) time.Time { return time.Date(2026, 7, 6, 7, min, 0, 0, time.Local) }
+	now := at(60).Add(45 * time.Second)
+	cases := []struct {
+		name      string
+		opts      []JobOption
+		fireTimes []time.Time
+		misfired  int
+	}{
+		{"fire once", nil, []time.Time{at(60)}, 3},
+		{"fire all", []JobOption{WithMisfire(MisfireFireAll)}, []time.Time{at(58), at(59), at(60)}, 3},
+		{"skip", []JobOption{WithMisfire(MisfireSkip)}, nil, 3},
+		{"skip later than threshold", []JobOption{WithMisfire(MisfireSkip), WithMisfireThreshold(90 * time.Second)},
+			[]time.Time{at(60)}, 2},
+		{"skip runs every on time fire", []JobOption{WithMisfire(MisfireSkip), WithMisfireThreshold(150 * time.Second)},
+			[]time.Time{at(59), at(60)}, 1},
+		{"fire once runs every on time fire", []JobOption{WithMisfireThreshold(150 * time.Second)},
+			[]time.Time{at(58), at(59), at(60)}, 1},
+	}
+	for _, c := range cases {
+		clock := NewFakeClock(at(57).Add(30 * time.Second))
+		rec := newRecorder()
+		s := New(WithClock(clock), WithEventHandler(rec.handle))
+		id := s.AddSchedule(minutely, func() {}, c.opts...)
+		s.Start()
+		clock.BlockUntil(1)
+		<-s.Stop().Done()
+		clock.Set(now)
+		s.Start()
+		for i := 0; i < c.misfired; i++ {
+			rec.wait(t, JobMisfired)
+		}
+		for range c.fireTimes {
+			rec.wait(t, JobSucceeded)
+		}
+		<-s.Stop().Done()
+
+		//同时开始的执行结束顺序不定
+		var fireTimes []time.Time
+		for _, e := range s.History(id) {
+			fireTimes = append(fireTimes, e.FireTime)
+		}
+		sort.Slice(fireTimes, func(i, j int) bool { return fireTimes[i].Before(fireTimes[j]) })
+		if !reflect.DeepEqual(fireTimes, c.fireTimes) {
+			t.Errorf("%s: ran %v, want %v", c.name, fireTimes, c.fireTimes)
+		}
+		if n := rec.count(JobMisfired); n != 0 {
+			t.Errorf("%s: %d more misfired events, want %d", c.name, n, c.misfired)
+		}
+	}
+}
+
+func TestMaxMisfires(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	rec := newRecorder()
+	s := New(WithClock(clock), WithEventHandler(rec.handle))
+	secondly, _ := Every(time.Second)
+	s.AddSchedule(secondly, func() {}, WithMisfire(MisfireFireAll))
+	s.Start()
+	clock.BlockUntil(1)
+	<-s.Stop().Done()
+	//错过1200次，只处理前1000次
+	clock.Set(testStart.Add(1200 * time.Second))
+	s.Start()
+	for i := 0; i < maxMisfires; i++ {
+		if e := rec.wait(t, JobMisfired); e.Err != nil {
+			t.Fatalf("misfired event %d has error %v", i, e.Err)
+		}
+	}
+	e := rec.wait(t, JobMisfired)
+	if want := testStart.Add((maxMisfires + 1) * time.Second); e.Err == nil || !e.FireTime.Equal(want) {
+		t.Errorf("truncation event %+v, want an error from %v", e, want)
+	}
+	for i := 0; i < maxMisfires; i++ {
+		rec.wait(t, JobSucceeded)
+	}
+	<-s.Stop().Done()
+	if n := rec.count(JobSucceeded); n != 0 {
+		t.Errorf("%d executions more than %d", n, maxMisfires)
+	}
+}
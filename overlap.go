@@ -0,0 +1,63 @@
+package cron
+
+import (
+	"time"
+)
+
+// OverlapPolicy decides what happens when a job is due while a previous execution is still running.
+type OverlapPolicy int
+
+const (
+	// AllowOverlap starts another execution alongside the running ones.
+	AllowOverlap OverlapPolicy = iota
+	// SkipIfRunning skips the fire time.
+	SkipIfRunning
+	// QueueOne runs the fire time once the running execution has finished. Only one fire time
+	// is kept waiting, later ones are skipped.
+	QueueOne
+	// ReplaceRunning cancels the context of the running executions and starts a new one.
+	ReplaceRunning
+)
+
+// WithOverlap sets the overlap policy of the job, AllowOverlap by default.
+// Skipped fire times are reported as JobSkipped events.
+func WithOverlap(p OverlapPolicy) JobOption {
+	return func(j *job) {
+		j.overlap = p
+	}
+}
+
+// fire runs the attempt of j for fireTime according to its overlap policy, called with c.lock held.
+// block is passed on to submit. It returns the event of a skipped or dropped fire time, which is
+// emitted after c.lock is released.
+func (c *Scheduler) fire(j *job, fireTime time.Time, attempt int, block bool) *Event {
+	//还在队列里等待的也算正在执行
+	if j.running+j.queued > 0 {
+		switch j.overlap {
+		case SkipIfRunning:
+			return &Event{Type: JobSkipped, JobID: j.id, JobName: j.name, FireTime: fireTime, Attempt: attempt}
+		case QueueOne:
+			if j.pending != nil {
+				return &Event{Type: JobSkipped, JobID: j.id, JobName: j.name, FireTime: fireTime, Attempt: attempt}
+			}
+			j.pending = &queued{j: j, fireTime: fireTime, attempt: attempt}
+			return nil
+		case ReplaceRunning:
+			for _, cancel := range j.cancels {
+				cancel()
+			}
+		}
+	}
+	return c.submit(j, fireTime, attempt, block)
+}
+
+// runPending runs the fire time queued by QueueOne after the last execution has finished,
+// called with c.lock held
+func (c *Scheduler) runPending(j *job) *Event {
+	if j.pending == nil || j.running+j.queued > 0 || j.ctx.Err() != nil {
+		return nil
+	}
+	p := j.pending
+	j.pending = nil
+	return c.submit(j, p.fireTime, p.attempt, false)
+}
@@ -0,0 +1,98 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOverlap(t *testing.T) {
+	minutely, _ := Every(time.Minute)
+	minute := func(min int) time.Time { return testStart.Add(time.Duration(min) * time.Minute) }
+	newScheduler := func() (*Scheduler, *FakeClock, *recorder) {
+		clock := NewFakeClock(testStart)
+		rec := newRecorder()
+		return New(WithClock(clock), WithEventHandler(rec.handle)), clock, rec
+	}
+	//前进到下一分钟，等调度器处理完
+	tick := func(clock *FakeClock) {
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		s, clock, rec := newScheduler()
+		release := make(chan struct{})
+		s.AddSchedule(minutely, func() { <-release }, WithOverlap(SkipIfRunning))
+		s.Start()
+		tick(clock)
+		rec.wait(t, JobStarted)
+		for i := 2; i <= 4; i++ {
+			tick(clock)
+			if e := rec.wait(t, JobSkipped); !e.FireTime.Equal(minute(i)) {
+				t.Errorf("skipped %v, want %v", e.FireTime, minute(i))
+			}
+		}
+		close(release)
+		rec.wait(t, JobSucceeded)
+		<-s.Stop().Done()
+		if n := rec.count(JobStarted); n != 0 {
+			t.Errorf("started %d more executions, want 1", n)
+		}
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		s, clock, rec := newScheduler()
+		release := make(chan struct{})
+		var once sync.Once
+		id := s.AddSchedule(minutely, func() {
+			once.Do(func() { <-release })
+		}, WithOverlap(QueueOne))
+		s.Start()
+		tick(clock)
+		rec.wait(t, JobStarted)
+		//8:02等待，8:03跳过
+		tick(clock)
+		tick(clock)
+		if e := rec.wait(t, JobSkipped); !e.FireTime.Equal(minute(3)) {
+			t.Errorf("skipped %v, want %v", e.FireTime, minute(3))
+		}
+		close(release)
+		rec.wait(t, JobSucceeded)
+		rec.wait(t, JobSucceeded)
+		<-s.Stop().Done()
+		if h := s.History(id); len(h) != 2 || !h[1].FireTime.Equal(minute(2)) || !h[1].Start.Equal(minute(3)) {
+			t.Errorf("history %+v, want the 8:02 fire time run after the first execution", h)
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		s, clock, rec := newScheduler()
+		id := s.AddScheduleJob(minutely, FuncJob(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}), WithOverlap(ReplaceRunning))
+		s.Start()
+		tick(clock)
+		rec.wait(t, JobStarted)
+		tick(clock)
+		if e := rec.wait(t, JobFailed); !e.FireTime.Equal(minute(1)) {
+			t.Errorf("replaced %v, want %v", e.FireTime, minute(1))
+		}
+		rec.wait(t, JobStarted)
+		<-s.Stop().Done()
+		h := s.History(id)
+		if len(h) != 2 {
+			t.Fatalf("history %+v, want 2 executions", h)
+		}
+		for _, e := range h {
+			if e.Err != context.Canceled {
+				t.Errorf("execution error = %v, want context.Canceled", e.Err)
+			}
+		}
+		if n := rec.count(JobSkipped); n != 0 {
+			t.Errorf("skipped %d fire times, want 0", n)
+		}
+	})
+}
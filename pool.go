@@ -0,0 +1,144 @@
+package cron
+
+import (
+	"sort"
+	"time"
+)
+
+// QueuePolicy decides what happens to a fire time when the queue of waiting executions is full.
+type QueuePolicy int
+
+const (
+	// QueueDrop drops the fire time and emits a JobDropped event.
+	QueueDrop QueuePolicy = iota
+	// QueueBlock holds up the scheduler until the queue has room.
+	QueueBlock
+)
+
+// WithMaxConcurrency limits how many executions run at the same time across all jobs.
+// Fire times beyond the limit wait in a queue, ordered by job priority and then fire time.
+// An execution abandoned after its timeout keeps its worker until it returns.
+// n <= 0 means no limit, the default.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Scheduler) {
+		c.maxConcurrency = n
+	}
+}
+
+// WithQueueLimit limits how many fire times may wait for a free worker, handling the
+// fire times beyond the limit according to policy. n <= 0 means no limit, the default.
+func WithQueueLimit(n int, policy QueuePolicy) Option {
+	return func(c *Scheduler) {
+		c.queueLimit = n
+		c.queuePolicy = policy
+	}
+}
+
+// WithJobConcurrency limits how many executions of the job run at the same time, the rest
+// wait in the scheduler's queue. Like WithMaxConcurrency it counts executions abandoned after
+// their timeout until they return. n <= 0 means no limit, the default.
+func WithJobConcurrency(n int) JobOption {
+	return func(j *job) {
+		j.maxConcurrency = n
+	}
+}
+
+// WithPriority sets the priority of the job in the queue of waiting executions, higher first.
+// The default is 0.
+func WithPriority(p int) JobOption {
+	return func(j *job) {
+		j.priority = p
+	}
+}
+
+// queued 等待空闲worker的触发
+type queued struct {
+	j        *job
+	fireTime time.Time
+	attempt  int
+	seq      uint64
+}
+
+// canStart reports whether an execution of j can start now, called with c.lock held
+func (c *Scheduler) canStart(j *job) bool {
+	return (c.maxConcurrency <= 0 || c.active < c.maxConcurrency) &&
+		(j.maxConcurrency <= 0 || j.workers < j.maxConcurrency)
+}
+
+// submit starts an execution of j or queues it when no worker is free, called with c.lock held.
+// If block is true and the queue is full with QueueBlock, it waits for room.
+// It returns the event of a dropped fire time, which is emitted after c.lock is released.
+func (c *Scheduler) submit(j *job, fireTime time.Time, attempt int, block bool) *Event {
+	if c.canStart(j) {
+		c.start(j, fireTime, attempt)
+		return nil
+	}
+	if c.queueLimit > 0 && len(c.queue) >= c.queueLimit {
+		if !block || c.queuePolicy == QueueDrop {
+			return &Event{Type: JobDropped, JobID: j.id, JobName: j.name, FireTime: fireTime, Attempt: attempt}
+		}
+		//等待队列有空位，Stop或Remove后放弃
+		for len(c.queue) >= c.queueLimit && c.running && c.jobMap[j.id] == j {
+			c.cond.Wait()
+		}
+		if !c.running || c.jobMap[j.id] != j {
+			return nil
+		}
+		if c.canStart(j) {
+			c.start(j, fireTime, attempt)
+			return nil
+		}
+	}
+	c.queueSeq++
+	q := &queued{j: j, fireTime: fireTime, attempt: attempt, seq: c.queueSeq}
+	i := sort.Search(len(c.queue), func(i int) bool {
+		p := c.queue[i]
+		if p.j.priority != j.priority {
+			return p.j.priority < j.priority
+		}
+		if !p.fireTime.Equal(fireTime) {
+			return p.fireTime.After(fireTime)
+		}
+		return false
+	})
+	c.queue = append(c.queue, nil)
+	copy(c.queue[i+1:], c.queue[i:])
+	c.queue[i] = q
+	j.queued++
+	return nil
+}
+
+// dispatch starts the queued executions that have a free worker, in queue order, called with c.lock held
+func (c *Scheduler) dispatch() {
+	if !c.running {
+		return
+	}
+	for i := 0; i < len(c.queue) && (c.maxConcurrency <= 0 || c.active < c.maxConcurrency); {
+		q := c.queue[i]
+		if !c.canStart(q.j) {
+			i++
+			continue
+		}
+		c.queue = append(c.queue[:i], c.queue[i+1:]...)
+		q.j.queued--
+		c.start(q.j, q.fireTime, q.attempt)
+		c.cond.Broadcast()
+	}
+}
+
+// dropQueued removes the queued executions of j, or all of them if j is nil, called with c.lock held
+func (c *Scheduler) dropQueued(j *job) {
+	queue := c.queue[:0]
+	for _, q := range c.queue {
+		if j != nil && q.j != j {
+			queue = append(queue, q)
+		} else {
+			q.j.queued--
+		}
+	}
+	for i := len(queue); i < len(c.queue); i++ {
+		c.queue[i] = nil
+	}
+	c.queue = queue
+	c.cond.Broadcast()
+}
@@ -0,0 +1,160 @@
+package cron
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaxConcurrency(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	rec := newRecorder()
+	s := New(WithClock(clock), WithMaxConcurrency(2), WithEventHandler(rec.handle))
+	minutely, _ := Every(time.Minute)
+	var cur, max int32
+	entered, release := make(chan struct{}), make(chan struct{})
+	for i := 0; i < 5; i++ {
+		s.AddSchedule(minutely, func() {
+			n := atomic.AddInt32(&cur, 1)
+			for m := atomic.LoadInt32(&max); n > m && !atomic.CompareAndSwapInt32(&max, m, n); m = atomic.LoadInt32(&max) {
+			}
+			entered <- struct{}{}
+			<-release
+			atomic.AddInt32(&cur, -1)
+		})
+	}
+	s.Start()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	//两个都进来后每放行一个，排队的下一个才开始
+	<-entered
+	<-entered
+	for i := 0; i < 5; i++ {
+		release <- struct{}{}
+		if i < 3 {
+			<-entered
+		}
+	}
+	for i := 0; i < 5; i++ {
+		rec.wait(t, JobSucceeded)
+	}
+	<-s.Stop().Done()
+	if m := atomic.LoadInt32(&max); m != 2 {
+		t.Errorf("max concurrent executions = %d, want 2", m)
+	}
+}
+
+// runOrder records the order things happen in across jobs
+type runOrder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (o *runOrder) add(name string) {
+	o.mu.Lock()
+	o.order = append(o.order, name)
+	o.mu.Unlock()
+}
+
+func (o *runOrder) check(t *testing.T, want ...string) {
+	t.Helper()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !reflect.DeepEqual(o.order, want) {
+		t.Errorf("order = %v, want %v", o.order, want)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	rec := newRecorder()
+	s := New(WithClock(clock), WithMaxConcurrency(1), WithQueueLimit(2, QueueDrop), WithEventHandler(rec.handle))
+	at := MustParse("0 1 8 * * ?")
+	var order runOrder
+	release := make(chan struct{})
+	add := func(name string, opts ...JobOption) uint {
+		return s.AddSchedule(at, func() {
+			order.add(name)
+			if name == "blocker" {
+				<-release
+			}
+		}, opts...)
+	}
+	add("blocker")
+	add("low")
+	add("high", WithPriority(10))
+	dropped := add("dropped")
+	s.Start()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	if e := rec.wait(t, JobDropped); e.JobID != dropped {
+		t.Errorf("dropped job %d, want %d", e.JobID, dropped)
+	}
+	close(release)
+	for i := 0; i < 3; i++ {
+		rec.wait(t, JobSucceeded)
+	}
+	<-s.Stop().Done()
+	order.check(t, "blocker", "high", "low")
+}
+
+func TestQueueBlock(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	rec := newRecorder()
+	s := New(WithClock(clock), WithQueueLimit(1, QueueBlock), WithEventHandler(rec.handle))
+	minutely, _ := Every(time.Minute)
+	var order runOrder
+	release := make(chan struct{})
+	var first sync.Once
+	s.AddSchedule(minutely, func() {
+		first.Do(func() {
+			<-release
+			order.add("first returned")
+		})
+	}, WithJobConcurrency(1))
+	s.AddSchedule(MustParse("0 3 8 * * ?"), func() { order.add("other") })
+	s.Start()
+
+	//8:01开始执行，8:02排队，8:03队列已满，调度器等到有空位
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	rec.wait(t, JobStarted)
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	close(release)
+	for i := 0; i < 4; i++ {
+		rec.wait(t, JobSucceeded)
+	}
+	<-s.Stop().Done()
+	order.check(t, "first returned", "other")
+}
+
+func TestTimedOutKeepsWorker(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	rec := newRecorder()
+	s := New(WithClock(clock), WithMaxConcurrency(1), WithEventHandler(rec.handle))
+	at := MustParse("0 1 8 * * ?")
+	var order runOrder
+	release := make(chan struct{})
+	s.AddSchedule(at, func() {
+		<-release
+		order.add("hung returned")
+	}, WithTimeout(10*time.Second))
+	s.AddSchedule(at, func() { order.add("other") })
+	s.Start()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	rec.wait(t, JobStarted)
+	//超时后不再等待，但worker要等任务真正返回才释放
+	clock.Advance(10 * time.Second)
+	rec.wait(t, JobTimedOut)
+	close(release)
+	rec.wait(t, JobSucceeded)
+	<-s.Stop().Done()
+	order.check(t, "hung returned", "other")
+}
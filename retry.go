@@ -0,0 +1,87 @@
+package cron
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy retries a failed execution, one that returned an error, panicked or timed out.
+// Retries that would start at or after the job's next scheduled fire time are given up.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one.
+	MaxAttempts int
+	// Backoff is the delay before the first retry.
+	Backoff time.Duration
+	// Multiplier multiplies the delay after each retry, values <= 1 keep it fixed.
+	Multiplier float64
+	// MaxBackoff caps the delay if > 0.
+	MaxBackoff time.Duration
+	// Jitter randomly changes each delay by up to this fraction of it, e.g. 0.2 for ±20%.
+	Jitter float64
+	// RetryIf reports whether err should be retried, all errors are if nil.
+	RetryIf func(err error) bool
+}
+
+// WithRetry retries failed executions of the job according to p. Retries wait in timers and
+// do not hold up the scheduler; a JobRetrying event is emitted when one is scheduled. When
+// due, a retry goes through the overlap policy of the job like a fire time.
+func WithRetry(p RetryPolicy) JobOption {
+	return func(j *job) {
+		j.retry = &p
+	}
+}
+
+// Attempt returns the attempt number of the execution running with ctx, 1 for the scheduled
+// run and greater for retries.
+func Attempt(ctx context.Context) int {
+	if n, ok := ctx.Value(attemptKey).(int); ok {
+		return n
+	}
+	return 1
+}
+
+// backoff returns the delay before the retry following attempt
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.Backoff)
+	if p.Multiplier > 1 {
+		delay *= math.Pow(p.Multiplier, float64(attempt-1))
+	}
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// retry schedules the next attempt of a failed execution, called with c.lock held.
+// It returns the JobRetrying event, which is emitted after c.lock is released.
+func (c *Scheduler) retry(j *job, fireTime time.Time, attempt int, err error) *Event {
+	p := j.retry
+	if p == nil || err == nil || attempt >= p.MaxAttempts || j.ctx.Err() != nil || (p.RetryIf != nil && !p.RetryIf(err)) {
+		return nil
+	}
+	delay := p.backoff(attempt)
+	if next := *j.nextTime; !next.IsZero() && !c.clock.Now().Add(delay).Before(next) {
+		return nil
+	}
+	ctx := j.ctx
+	c.clock.AfterFunc(delay, func() {
+		c.lock.Lock()
+		//已经Stop或Remove
+		if ctx.Err() != nil {
+			c.lock.Unlock()
+			return
+		}
+		//重试也要遵守重叠策略
+		e := c.fire(j, fireTime, attempt+1, false)
+		c.lock.Unlock()
+		if e != nil {
+			c.emit(*e)
+		}
+	})
+	return &Event{Type: JobRetrying, JobID: j.id, JobName: j.name, FireTime: fireTime, Attempt: attempt + 1, Err: err}
+}
@@ -0,0 +1,149 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second, Multiplier: 2, MaxBackoff: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+	p = RetryPolicy{Backoff: time.Second, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(3); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("backoff with jitter = %v, want within ±50%% of 1s", got)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	rec := newRecorder()
+	s := New(WithClock(clock), WithEventHandler(rec.handle))
+	at := MustParse("0 1 8 * * ?")
+	transient := errors.New("transient")
+	id := s.AddScheduleJob(at, FuncJob(func(ctx context.Context) error {
+		if Attempt(ctx) < 3 {
+			return transient
+		}
+		return nil
+	}), WithRetry(RetryPolicy{MaxAttempts: 5, Backoff: time.Second, Multiplier: 2}))
+	permanent := errors.New("permanent")
+	failing := s.AddScheduleJob(at, FuncJob(func(ctx context.Context) error {
+		return permanent
+	}), WithRetry(RetryPolicy{MaxAttempts: 5, Backoff: time.Second, RetryIf: func(err error) bool {
+		return err == transient
+	}}))
+	s.Start()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	fireTime := clock.Now()
+	//退避1s、2s
+	for _, d := range []time.Duration{time.Second, 2 * time.Second} {
+		if e := rec.wait(t, JobRetrying); e.JobID != id {
+			t.Errorf("job %d retrying, want %d", e.JobID, id)
+		}
+		clock.Advance(d)
+	}
+	rec.wait(t, JobSucceeded)
+	<-s.Stop().Done()
+
+	history := s.History(id)
+	if len(history) != 3 {
+		t.Fatalf("history %+v, want 3 attempts", history)
+	}
+	for i, e := range history {
+		if e.Attempt != i+1 || !e.FireTime.Equal(fireTime) {
+			t.Errorf("execution %d: attempt %d fire time %v", i, e.Attempt, e.FireTime)
+		}
+	}
+	if history[2].Err != nil || rec.count(JobRetrying) != 0 {
+		t.Errorf("history %+v, %d more retrying events", history, rec.count(JobRetrying))
+	}
+	if n := len(s.History(failing)); n != 1 {
+		t.Errorf("permanent error executed %d times, want 1", n)
+	}
+}
+
+func TestRetryBeforeNextFire(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	rec := newRecorder()
+	s := New(WithClock(clock), WithEventHandler(rec.handle))
+	minutely, _ := Every(time.Minute)
+	id := s.AddScheduleJob(minutely, FuncJob(func(ctx context.Context) error {
+		return errors.New("failure")
+	}), WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Hour}))
+	s.Start()
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		rec.wait(t, JobFailed)
+	}
+	<-s.Stop().Done()
+	if n := rec.count(JobRetrying); n != 0 {
+		t.Errorf("%d retries scheduled past the next fire time", n)
+	}
+	for _, e := range s.History(id) {
+		if e.Attempt != 1 {
+			t.Errorf("retried at attempt %d past the next fire time", e.Attempt)
+		}
+	}
+}
+
+func TestRetryOverlap(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	rec := newRecorder()
+	s := New(WithClock(clock), WithEventHandler(rec.handle))
+	first := testStart.Add(time.Minute)
+	var order runOrder
+	fail, release, retried := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	id := s.AddScheduleJob(MustParse("0,10 1 8 * * ?"), FuncJob(func(ctx context.Context) error {
+		fireTime, _ := FireTime(ctx)
+		switch {
+		case Attempt(ctx) > 1:
+			order.add("retry")
+			close(retried)
+		case fireTime.Equal(first):
+			<-fail
+			return errors.New("failure")
+		default:
+			<-release
+			order.add("second returned")
+		}
+		return nil
+	}), WithOverlap(QueueOne), WithRetry(RetryPolicy{MaxAttempts: 2, Backoff: 2 * time.Second}))
+	s.Start()
+
+	//8:01:10的触发等8:01:00的执行结束
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	rec.wait(t, JobStarted)
+	clock.Advance(10 * time.Second)
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Second)
+	close(fail)
+	rec.wait(t, JobRetrying)
+	rec.wait(t, JobStarted)
+	//重试到期时8:01:10的执行还在运行，按QueueOne等它结束
+	clock.Advance(2 * time.Second)
+	select {
+	case <-retried:
+		t.Error("retry ran alongside the running execution")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	rec.wait(t, JobSucceeded)
+	rec.wait(t, JobSucceeded)
+	<-s.Stop().Done()
+	order.check(t, "second returned", "retry")
+	if h := s.History(id); len(h) != 3 || h[2].Attempt != 2 || !h[2].FireTime.Equal(first) {
+		t.Errorf("history %+v, want the retry of the first fire time last", h)
+	}
+}
@@ -0,0 +1,597 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRRulePeriods limits how many consecutive periods without an occurrence are inspected,
+// Next also gives up maxYears after t
+const maxRRulePeriods = 100000
+
+type rruleFreq int
+
+const (
+	freqSecondly rruleFreq = iota
+	freqMinutely
+	freqHourly
+	freqDaily
+	freqWeekly
+	freqMonthly
+	freqYearly
+)
+
+var (
+	rruleFreqs = map[string]rruleFreq{
+		"SECONDLY": freqSecondly,
+		"MINUTELY": freqMinutely,
+		"HOURLY":   freqHourly,
+		"DAILY":    freqDaily,
+		"WEEKLY":   freqWeekly,
+		"MONTHLY":  freqMonthly,
+		"YEARLY":   freqYearly,
+	}
+	rruleWeekdays = map[string]time.Weekday{
+		"SU": time.Sunday,
+		"MO": time.Monday,
+		"TU": time.Tuesday,
+		"WE": time.Wednesday,
+		"TH": time.Thursday,
+		"FR": time.Friday,
+		"SA": time.Saturday,
+	}
+)
+
+// rruleWeekday is a BYDAY entry, e.g. -1FR is {n: -1, weekday: time.Friday}
+type rruleWeekday struct {
+	n       int
+	weekday time.Weekday
+}
+
+// rruleSchedule is a recurrence rule as defined by RFC 5545 section 3.3.10
+type rruleSchedule struct {
+	rule       string
+	dtstart    time.Time
+	freq       rruleFreq
+	interval   int
+	count      int
+	until      time.Time
+	bySecond   []int
+	byMinute   []int
+	byHour     []int
+	byDay      []rruleWeekday
+	byMonthDay []int
+	byYearDay  []int
+	byMonth    []int
+	bySetPos   []int
+	wkst       time.Weekday
+	exdates    []time.Time
+	exdays     map[[3]int]struct{}
+}
+
+// ParseRRule parses an iCalendar recurrence rule. s holds the RRULE and optionally DTSTART and
+// EXDATE content lines, one per line:
+//
+//	DTSTART;TZID=Asia/Shanghai:20260105T090000
+//	RRULE:FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=18
+//	EXDATE;TZID=Asia/Shanghai:20260130T180000
+//
+// A bare rule such as FREQ=WEEKLY;BYDAY=MO is accepted as well. Without DTSTART the rule starts
+// at 1970-01-01T00:00:00 local time. BYWEEKNO is not supported.
+func ParseRRule(s string) (Schedule, error) {
+	r := &rruleSchedule{interval: 1, wkst: time.Monday, dtstart: time.Date(1970, 1, 1, 0, 0, 0, 0, time.Local)}
+	var exdates [][2]string
+	hasRule := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "FREQ=") {
+			line = "RRULE:" + line
+		}
+		index := strings.IndexByte(line, ':')
+		if index < 0 {
+			return nil, errors.New(fmt.Sprintf("rrule line %s should be NAME:VALUE", line))
+		}
+		name, params, value := line[:index], "", line[index+1:]
+		if i := strings.IndexByte(name, ';'); i > -1 {
+			name, params = name[:i], name[i+1:]
+		}
+		switch strings.ToUpper(name) {
+		case "DTSTART":
+			loc, err := icalLocation(params, time.Local)
+			if err != nil {
+				return nil, err
+			}
+			r.dtstart, _, err = parseICalTime(value, loc)
+			if err != nil {
+				return nil, err
+			}
+		case "RRULE":
+			if hasRule {
+				return nil, errors.New("rrule should contain only one RRULE")
+			}
+			hasRule = true
+			r.rule = value
+		case "EXDATE":
+			exdates = append(exdates, [2]string{params, value})
+		default:
+			return nil, errors.New(fmt.Sprintf("rrule property %s is not supported", name))
+		}
+	}
+	if !hasRule {
+		return nil, errors.New("rrule should contain a RRULE")
+	}
+	if err := r.parseRule(); err != nil {
+		return nil, err
+	}
+	for _, exdate := range exdates {
+		loc, err := icalLocation(exdate[0], r.dtstart.Location())
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range strings.Split(exdate[1], ",") {
+			t, dateOnly, err := parseICalTime(value, loc)
+			if err != nil {
+				return nil, err
+			}
+			if dateOnly {
+				if r.exdays == nil {
+					r.exdays = make(map[[3]int]struct{})
+				}
+				y, m, d := t.Date()
+				r.exdays[[3]int{y, int(m), d}] = struct{}{}
+			} else {
+				r.exdates = append(r.exdates, t)
+			}
+		}
+	}
+	return r, nil
+}
+
+func (r *rruleSchedule) parseRule() (err error) {
+	hasFreq := false
+	for _, part := range strings.Split(r.rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return errors.New(fmt.Sprintf("rrule part %s should be NAME=VALUE", part))
+		}
+		name, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		switch name {
+		case "FREQ":
+			var ok bool
+			if r.freq, ok = rruleFreqs[value]; !ok {
+				return errors.New(fmt.Sprintf("rrule FREQ %s is not supported", value))
+			}
+			hasFreq = true
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err != nil || r.interval < 1 {
+				return errors.New(fmt.Sprintf("rrule INTERVAL %s should be a positive integer", value))
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err != nil || r.count < 1 {
+				return errors.New(fmt.Sprintf("rrule COUNT %s should be a positive integer", value))
+			}
+		case "UNTIL":
+			var dateOnly bool
+			r.until, dateOnly, err = parseICalTime(value, r.dtstart.Location())
+			if err != nil {
+				return err
+			}
+			if dateOnly {
+				r.until = r.until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+		case "BYSECOND":
+			r.bySecond, err = parseRRuleInts(name, value, 0, 59, false)
+		case "BYMINUTE":
+			r.byMinute, err = parseRRuleInts(name, value, 0, 59, false)
+		case "BYHOUR":
+			r.byHour, err = parseRRuleInts(name, value, 0, 23, false)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseRRuleInts(name, value, 1, 31, true)
+		case "BYYEARDAY":
+			r.byYearDay, err = parseRRuleInts(name, value, 1, 366, true)
+		case "BYMONTH":
+			r.byMonth, err = parseRRuleInts(name, value, 1, 12, false)
+		case "BYSETPOS":
+			r.bySetPos, err = parseRRuleInts(name, value, 1, 366, true)
+		case "BYDAY":
+			r.byDay, err = parseRRuleWeekdays(value)
+		case "WKST":
+			var ok bool
+			if r.wkst, ok = rruleWeekdays[value]; !ok {
+				return errors.New(fmt.Sprintf("rrule WKST %s should be one of SU,MO,TU,WE,TH,FR,SA", value))
+			}
+		default:
+			return errors.New(fmt.Sprintf("rrule part %s is not supported", name))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !hasFreq {
+		return errors.New("rrule FREQ is required")
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return errors.New("rrule COUNT and UNTIL should not both be set")
+	}
+	for _, wd := range r.byDay {
+		if wd.n != 0 && r.freq != freqMonthly && r.freq != freqYearly {
+			return errors.New("rrule BYDAY with a number is only allowed with FREQ=MONTHLY or FREQ=YEARLY")
+		}
+	}
+	//BYMONTH=2;BYMONTHDAY=30永远不会发生
+	if len(r.byMonthDay) > 0 && !r.monthDayOccurs() {
+		return errors.New(fmt.Sprintf("rrule BYMONTHDAY never occurs in %s", r.rule))
+	}
+	return nil
+}
+
+// monthDayOccurs reports whether a BYMONTHDAY value exists in one of the months of BYMONTH
+func (r *rruleSchedule) monthDayOccurs() bool {
+	for m := 1; m <= 12; m++ {
+		if len(r.byMonth) > 0 && !containsInt(r.byMonth, m) {
+			continue
+		}
+		//闰年的天数
+		max := getYearMonthDays(2000, m)
+		for _, d := range r.byMonthDay {
+			if d <= max && -d <= max {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *rruleSchedule) Next(t time.Time) time.Time {
+	k, seen := 0, 0
+	if r.count == 0 {
+		k = r.estimate(t)
+	}
+	limit := t
+	if limit.Before(r.dtstart) {
+		limit = r.dtstart
+	}
+	limit = limit.AddDate(maxYears, 0, 0)
+	for empty := 0; empty < maxRRulePeriods; k++ {
+		p := r.period(k)
+		if (!r.until.IsZero() && p.After(r.until)) || p.After(limit) {
+			return time.Time{}
+		}
+		instances := r.instances(p)
+		if len(instances) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+		for _, instance := range instances {
+			if instance.Before(r.dtstart) {
+				continue
+			}
+			if !r.until.IsZero() && instance.After(r.until) {
+				return time.Time{}
+			}
+			seen++
+			if r.count > 0 && seen > r.count {
+				return time.Time{}
+			}
+			if instance.After(t) && !r.excluded(instance) {
+				return instance
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// period returns the start of the k-th period after DTSTART
+func (r *rruleSchedule) period(k int) time.Time {
+	d := r.dtstart
+	loc := d.Location()
+	n := k * r.interval
+	switch r.freq {
+	case freqYearly:
+		return dayStart(d.Year()+n, 1, 1, loc)
+	case freqMonthly:
+		return dayStart(d.Year(), d.Month()+time.Month(n), 1, loc)
+	case freqWeekly:
+		w := r.weekStart(d)
+		return dayStart(w.Year(), w.Month(), w.Day()+7*n, loc)
+	case freqDaily:
+		return dayStart(d.Year(), d.Month(), d.Day()+n, loc)
+	case freqHourly:
+		return time.Date(d.Year(), d.Month(), d.Day(), d.Hour(), 0, 0, 0, loc).Add(time.Duration(n) * time.Hour)
+	case freqMinutely:
+		return time.Date(d.Year(), d.Month(), d.Day(), d.Hour(), d.Minute(), 0, 0, loc).Add(time.Duration(n) * time.Minute)
+	default:
+		return d.Truncate(time.Second).Add(time.Duration(n) * time.Second)
+	}
+}
+
+// estimate returns a period index not after the period containing t, so that Next does not
+// have to walk from DTSTART
+func (r *rruleSchedule) estimate(t time.Time) int {
+	d := r.dtstart
+	t = t.In(d.Location())
+	var units int
+	switch r.freq {
+	case freqYearly:
+		units = t.Year() - d.Year()
+	case freqMonthly:
+		units = (t.Year()-d.Year())*12 + int(t.Month()) - int(d.Month())
+	case freqWeekly:
+		units = civilDays(r.weekStart(d), t) / 7
+	case freqDaily:
+		units = civilDays(d, t)
+	case freqHourly:
+		units = int(t.Sub(r.period(0)) / time.Hour)
+	case freqMinutely:
+		units = int(t.Sub(r.period(0)) / time.Minute)
+	default:
+		units = int(t.Sub(r.period(0)) / time.Second)
+	}
+	if k := units/r.interval - 1; k > 0 {
+		return k
+	}
+	return 0
+}
+
+func (r *rruleSchedule) weekStart(d time.Time) time.Time {
+	return dayStart(d.Year(), d.Month(), d.Day()-int((d.Weekday()-r.wkst+7)%7), d.Location())
+}
+
+// instances returns the sorted occurrences of the period starting at p, after BYSETPOS is applied
+func (r *rruleSchedule) instances(p time.Time) []time.Time {
+	var days []time.Time
+	y, m, d := p.Date()
+	loc := p.Location()
+	switch r.freq {
+	case freqYearly:
+		for day := p; day.Year() == y; day = dayStart(y, m, d+len(days), loc) {
+			days = append(days, day)
+		}
+	case freqMonthly:
+		for day := p; day.Month() == m; day = dayStart(y, m, d+len(days), loc) {
+			days = append(days, day)
+		}
+	case freqWeekly:
+		for i := 0; i < 7; i++ {
+			days = append(days, dayStart(y, m, d+i, loc))
+		}
+	default:
+		days = append(days, midnight(p))
+	}
+	hours := r.expand(r.byHour, r.dtstart.Hour(), p.Hour(), r.freq <= freqHourly)
+	minutes := r.expand(r.byMinute, r.dtstart.Minute(), p.Minute(), r.freq <= freqMinutely)
+	seconds := r.expand(r.bySecond, r.dtstart.Second(), p.Second(), r.freq == freqSecondly)
+	var set []time.Time
+	for _, day := range days {
+		if !r.matchDay(day) {
+			continue
+		}
+		for _, h := range hours {
+			for _, m := range minutes {
+				for _, s := range seconds {
+					set = append(set, icalDate(day.Year(), day.Month(), day.Day(), h, m, s, loc))
+				}
+			}
+		}
+	}
+	sort.Slice(set, func(i, j int) bool {
+		return set[i].Before(set[j])
+	})
+	if len(r.bySetPos) == 0 || len(set) == 0 {
+		return set
+	}
+	var selected []time.Time
+	for _, pos := range r.bySetPos {
+		if pos > 0 && pos <= len(set) {
+			selected = append(selected, set[pos-1])
+		} else if pos < 0 && -pos <= len(set) {
+			selected = append(selected, set[len(set)+pos])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Before(selected[j])
+	})
+	var result []time.Time
+	for i, t := range selected {
+		if i == 0 || !t.Equal(selected[i-1]) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// expand returns the values of a time field: the period's own value limited by the BYxxx list
+// when the frequency is at least as fine as the field, otherwise the BYxxx list or the DTSTART value
+func (r *rruleSchedule) expand(by []int, start, own int, limit bool) []int {
+	if limit {
+		if len(by) == 0 || containsInt(by, own) {
+			return []int{own}
+		}
+		return nil
+	}
+	if len(by) == 0 {
+		return []int{start}
+	}
+	return by
+}
+
+func (r *rruleSchedule) matchDay(day time.Time) bool {
+	y, m, d := day.Date()
+	if len(r.byMonth) > 0 && !containsInt(r.byMonth, int(m)) {
+		return false
+	}
+	if len(r.byYearDay) > 0 {
+		yearDays := 365
+		if getYearMonthDays(y, 2) == 29 {
+			yearDays = 366
+		}
+		if yd := day.YearDay(); !containsInt(r.byYearDay, yd) && !containsInt(r.byYearDay, yd-yearDays-1) {
+			return false
+		}
+	}
+	if len(r.byMonthDay) > 0 {
+		if max := getYearMonthDays(y, int(m)); !containsInt(r.byMonthDay, d) && !containsInt(r.byMonthDay, d-max-1) {
+			return false
+		}
+	}
+	if len(r.byDay) > 0 && !r.matchWeekday(day) {
+		return false
+	}
+	//没有指定日期规则时取DTSTART的日期
+	noDayRule := len(r.byYearDay) == 0 && len(r.byMonthDay) == 0 && len(r.byDay) == 0
+	switch r.freq {
+	case freqYearly:
+		if noDayRule && len(r.byMonth) == 0 {
+			return m == r.dtstart.Month() && d == r.dtstart.Day()
+		}
+		if noDayRule {
+			return d == r.dtstart.Day()
+		}
+	case freqMonthly:
+		if noDayRule {
+			return d == r.dtstart.Day()
+		}
+	case freqWeekly:
+		if noDayRule {
+			return day.Weekday() == r.dtstart.Weekday()
+		}
+	}
+	return true
+}
+
+func (r *rruleSchedule) matchWeekday(day time.Time) bool {
+	for _, wd := range r.byDay {
+		if day.Weekday() != wd.weekday {
+			continue
+		}
+		if wd.n == 0 {
+			return true
+		}
+		//第n个星期几在年内还是月内计算
+		first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		last := first.AddDate(0, 1, -1)
+		if r.freq == freqYearly && len(r.byMonth) == 0 {
+			first = time.Date(day.Year(), 1, 1, 0, 0, 0, 0, day.Location())
+			last = time.Date(day.Year(), 12, 31, 0, 0, 0, 0, day.Location())
+		}
+		if wd.n > 0 && civilDays(first, day)/7+1 == wd.n {
+			return true
+		}
+		if wd.n < 0 && civilDays(day, last)/7+1 == -wd.n {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rruleSchedule) excluded(t time.Time) bool {
+	for _, exdate := range r.exdates {
+		if exdate.Equal(t) {
+			return true
+		}
+	}
+	y, m, d := t.Date()
+	_, ok := r.exdays[[3]int{y, int(m), d}]
+	return ok
+}
+
+func (r *rruleSchedule) rrule() (time.Time, string, []time.Time, bool) {
+	return r.dtstart, r.rule, r.exdates, len(r.exdays) == 0
+}
+
+func parseRRuleInts(name, value string, min, max int, negative bool) ([]int, error) {
+	var values []int
+	for _, str := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(str)
+		abs := v
+		if abs < 0 && negative {
+			abs = -abs
+		}
+		if err != nil || abs < min || abs > max {
+			return nil, errors.New(fmt.Sprintf("rrule %s value %s should be in [%d,%d]", name, str, min, max))
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func parseRRuleWeekdays(value string) ([]rruleWeekday, error) {
+	var values []rruleWeekday
+	for _, str := range strings.Split(value, ",") {
+		if len(str) < 2 {
+			return nil, errors.New(fmt.Sprintf("rrule BYDAY value %s is invalid", str))
+		}
+		wd, ok := rruleWeekdays[str[len(str)-2:]]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("rrule BYDAY value %s is invalid", str))
+		}
+		var n int
+		if num := str[:len(str)-2]; num != "" {
+			var err error
+			n, err = strconv.Atoi(num)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, errors.New(fmt.Sprintf("rrule BYDAY value %s is invalid", str))
+			}
+		}
+		values = append(values, rruleWeekday{n: n, weekday: wd})
+	}
+	return values, nil
+}
+
+// icalLocation returns the location named by the TZID parameter, or def if there is none
+func icalLocation(params string, def *time.Location) (*time.Location, error) {
+	for _, param := range strings.Split(params, ";") {
+		if kv := strings.SplitN(param, "=", 2); len(kv) == 2 && strings.ToUpper(kv[0]) == "TZID" {
+			return time.LoadLocation(strings.Trim(kv[1], `"`))
+		}
+	}
+	return def, nil
+}
+
+// parseICalTime parses an iCalendar DATE or DATE-TIME value. Values without a trailing Z are
+// interpreted in loc.
+func parseICalTime(value string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	switch {
+	case len(value) == 8:
+		t, err = time.ParseInLocation("20060102", value, loc)
+		dateOnly = true
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse("20060102T150405Z", value)
+	default:
+		t, err = time.ParseInLocation("20060102T150405", value, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, errors.New(fmt.Sprintf("ical time %s should be YYYYMMDD or YYYYMMDDTHHMMSS[Z]", value))
+	}
+	return
+}
+
+// icalDate is time.Date, except that a local time skipped by DST is interpreted with the UTC
+// offset before the gap as RFC 5545 requires, so 02:30 on the day DST starts is 03:30
+func icalDate(year int, month time.Month, day, hour, min, sec int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, hour, min, sec, 0, loc)
+	if t.Hour() == hour && t.Minute() == min && t.Second() == sec {
+		return t
+	}
+	_, offset := time.Date(year, month, day-1, hour, min, sec, 0, loc).Zone()
+	return time.Date(year, month, day, hour, min, sec, 0, time.UTC).Add(-time.Duration(offset) * time.Second).In(loc)
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	cases := []struct {
+		rule string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			"DTSTART;TZID=Asia/Shanghai:20260101T090000\nRRULE:FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=18\nEXDATE;TZID=Asia/Shanghai:20260227T180000",
+			time.Date(2026, 1, 1, 0, 0, 0, 0, shanghai),
+			[]time.Time{
+				time.Date(2026, 1, 30, 18, 0, 0, 0, shanghai),
+				time.Date(2026, 3, 27, 18, 0, 0, 0, shanghai),
+			},
+		},
+		{
+			// 每月最后一个工作日，共3次
+			"DTSTART:20260101T010000Z\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=3",
+			time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			[]time.Time{
+				time.Date(2026, 1, 30, 1, 0, 0, 0, time.UTC),
+				time.Date(2026, 2, 27, 1, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 31, 1, 0, 0, 0, time.UTC),
+				{},
+			},
+		},
+		{
+			"DTSTART:20260105T083000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;UNTIL=20260121T000000Z",
+			time.Date(2026, 1, 5, 8, 30, 0, 0, time.UTC),
+			[]time.Time{
+				time.Date(2026, 1, 7, 8, 30, 0, 0, time.UTC),
+				time.Date(2026, 1, 19, 8, 30, 0, 0, time.UTC),
+				{},
+			},
+		},
+		{
+			"DTSTART:20240229T120000Z\nRRULE:FREQ=YEARLY",
+			time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			[]time.Time{time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			// 2026-03-08夏令时开始，2:30不存在，按跳变前的偏移是3:30
+			"DTSTART;TZID=America/New_York:20260301T000000\nRRULE:FREQ=DAILY;BYHOUR=2;BYMINUTE=30",
+			time.Date(2026, 3, 7, 12, 0, 0, 0, newYork),
+			[]time.Time{
+				time.Date(2026, 3, 8, 3, 30, 0, 0, newYork),
+				time.Date(2026, 3, 9, 2, 30, 0, 0, newYork),
+			},
+		},
+		{
+			// 1月没有第366天，30年后放弃
+			"FREQ=YEARLY;BYYEARDAY=366;BYMONTH=1",
+			time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			[]time.Time{{}},
+		},
+	}
+	for _, c := range cases {
+		s, err := ParseRRule(c.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", c.rule, err)
+		}
+		now := c.from
+		for _, w := range c.want {
+			if now = s.Next(now); !now.Equal(w) {
+				t.Errorf("%s: next = %v, want %v", c.rule, now, w)
+				break
+			}
+		}
+	}
+	for _, rule := range []string{"FREQ=DAILY;COUNT=2;UNTIL=20260101", "FREQ=WEEKLY;BYDAY=1MO", "BYDAY=MO", "FREQ=DAILY;BYWEEKNO=1", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", "FREQ=MONTHLY;BYMONTH=4,6;BYMONTHDAY=-31"} {
+		if _, err := ParseRRule(rule); err == nil {
+			t.Errorf("%s should be rejected", rule)
+		}
+	}
+}
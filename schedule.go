@@ -0,0 +1,182 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Schedule describes when a job runs.
+type Schedule interface {
+	// Next returns the first activation time strictly after t, or the zero time if there is none.
+	Next(t time.Time) time.Time
+}
+
+// ParseOption configures how a cron expression is parsed.
+type ParseOption func(t *trigger)
+
+// WithHolidays makes the days excluded by cal non-business days for W, LW and BD in the day field,
+// in addition to Saturdays and Sundays. A day is a holiday if cal excludes its midnight.
+func WithHolidays(cal Calendar) ParseOption {
+	return func(t *trigger) {
+		t.holidays = cal
+	}
+}
+
+// WithMilliseconds expects a leading millisecond field (0-999) before the seconds, e.g.
+// "0/250 * * * * * ?" fires every 250ms aligned to the wall clock.
+func WithMilliseconds() ParseOption {
+	return func(t *trigger) {
+		t.millis = true
+	}
+}
+
+// Parse parses a 6-field cron expression into a Schedule.
+func Parse(cronExpression string, opts ...ParseOption) (Schedule, error) {
+	return newTrigger(cronExpression, opts...)
+}
+
+// MustParse is like Parse but panics if the expression cannot be parsed.
+func MustParse(cronExpression string, opts ...ParseOption) Schedule {
+	s, err := Parse(cronExpression, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (t *trigger) Next(now time.Time) time.Time {
+	if t.ms == nil {
+		return *t.next(now.Truncate(time.Second).Add(time.Second))
+	}
+	//当前秒匹配时先在当前秒内找毫秒
+	sec := now.Truncate(time.Second)
+	if t.next(sec).Equal(sec) {
+		if ms, ok := t.ms.nextValue(uint(now.Sub(sec)/time.Millisecond) + 1); ok {
+			return sec.Add(time.Duration(ms) * time.Millisecond)
+		}
+	}
+	next := t.next(sec.Add(time.Second))
+	if next.IsZero() {
+		return *next
+	}
+	ms, _ := t.ms.nextValue(0)
+	return next.Add(time.Duration(ms) * time.Millisecond)
+}
+
+// nextFrom returns the first activation time of s at or after t
+func nextFrom(s Schedule, t time.Time) time.Time {
+	return s.Next(t.Add(-time.Nanosecond))
+}
+
+type unionSchedule []Schedule
+
+// Union returns a schedule that fires whenever any of the schedules fires.
+// Times shared by several schedules fire only once.
+func Union(schedules ...Schedule) Schedule {
+	return unionSchedule(schedules)
+}
+func (u unionSchedule) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, s := range u {
+		n := s.Next(t)
+		if !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next
+}
+
+type intersectSchedule []Schedule
+
+// Intersect returns a schedule that fires only when all of the schedules fire at the same time.
+// Its Next gives up maxYears after t.
+func Intersect(schedules ...Schedule) Schedule {
+	return intersectSchedule(schedules)
+}
+func (in intersectSchedule) Next(t time.Time) time.Time {
+	if len(in) == 0 {
+		return time.Time{}
+	}
+	next := in[0].Next(t)
+	limit := t.AddDate(maxYears, 0, 0)
+	for !next.IsZero() && !next.After(limit) {
+		//所有子表达式都在next触发则找到，否则从最晚的时间重新计算
+		latest, agreed := next, true
+		for _, s := range in {
+			n := nextFrom(s, next)
+			if n.IsZero() {
+				return time.Time{}
+			}
+			if !n.Equal(next) {
+				agreed = false
+			}
+			if n.After(latest) {
+				latest = n
+			}
+		}
+		if agreed {
+			return next
+		}
+		next = latest
+	}
+	return time.Time{}
+}
+
+type exceptSchedule struct {
+	s        Schedule
+	excluded Schedule
+}
+
+// Except returns a schedule that fires when s fires, except at the times excluded fires.
+// Its Next gives up maxYears after t.
+func Except(s, excluded Schedule) Schedule {
+	return &exceptSchedule{s: s, excluded: excluded}
+}
+func (e *exceptSchedule) Next(t time.Time) time.Time {
+	next := e.s.Next(t)
+	limit := t.AddDate(maxYears, 0, 0)
+	for !next.IsZero() && !next.After(limit) {
+		if !nextFrom(e.excluded, next).Equal(next) {
+			return next
+		}
+		next = e.skip(next)
+	}
+	return time.Time{}
+}
+
+// skip returns the next candidate after next, which is excluded. When both schedules are cron
+// expressions without milliseconds every fire time is a whole second, so the seconds matched by
+// excluded are skipped all at once instead of one by one.
+func (e *exceptSchedule) skip(next time.Time) time.Time {
+	s, ok := e.s.(*trigger)
+	excluded, ok2 := e.excluded.(*trigger)
+	if !ok || !ok2 || s.ms != nil || excluded.ms != nil {
+		return e.s.Next(next)
+	}
+	return nextFrom(e.s, (&cronCalendar{t: excluded}).includedAfter(next))
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Every returns a schedule firing every interval aligned to the wall clock: the fire times
+// are the multiples of interval after local midnight, e.g. Every(250*time.Millisecond) fires at
+// .000, .250, .500 and .750 of every second. The alignment restarts at midnight when interval
+// does not divide a day. interval should be in [1ms,24h].
+func Every(interval time.Duration) (Schedule, error) {
+	if interval < time.Millisecond || interval > 24*time.Hour {
+		return nil, errors.New(fmt.Sprintf("interval %s should be in [1ms,24h]", interval))
+	}
+	return &intervalSchedule{interval: interval}, nil
+}
+
+func (s *intervalSchedule) Next(t time.Time) time.Time {
+	day := midnight(t)
+	next := day.Add((t.Sub(day)/s.interval + 1) * s.interval)
+	if end := nextMidnight(t); !next.Before(end) {
+		return end
+	}
+	return next
+}
@@ -0,0 +1,81 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompositeSchedule(t *testing.T) {
+	s := Except(
+		Union(MustParse("0 0/15 9-17 ? * MON-FRI"), MustParse("0 0 8 ? * SAT")),
+		MustParse("* * * ? * 1#1"),
+	)
+	cases := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		// 2026-03-02 是当月第一个星期一
+		{time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local), time.Date(2026, 3, 3, 9, 0, 0, 0, time.Local)},
+		{time.Date(2026, 3, 3, 9, 0, 0, 0, time.Local), time.Date(2026, 3, 3, 9, 15, 0, 0, time.Local)},
+		{time.Date(2026, 3, 6, 17, 45, 0, 0, time.Local), time.Date(2026, 3, 7, 8, 0, 0, 0, time.Local)},
+	}
+	for _, c := range cases {
+		if got := s.Next(c.now); !got.Equal(c.want) {
+			t.Errorf("Next(%v) = %v, want %v", c.now, got, c.want)
+		}
+	}
+	in := Intersect(MustParse("0 0 10 * * ?"), MustParse("0 0 * 13 * ?"), MustParse("0 0 * ? * FRI"))
+	if got, want := in.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)), time.Date(2026, 2, 13, 10, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("Intersect.Next = %v, want %v", got, want)
+	}
+
+	//密集的触发减去整个周末，不能因为查找次数多就当作不再触发
+	friday := time.Date(2026, 3, 6, 23, 59, 59, 0, time.Local)
+	monday := time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local)
+	tenSeconds, _ := Every(10 * time.Second)
+	for _, s := range []Schedule{
+		Except(MustParse("* * * * * ?"), MustParse("* * * ? * SAT,SUN")),
+		Except(tenSeconds, MustParse("* * * ? * SAT,SUN")),
+	} {
+		if got := s.Next(friday); !got.Equal(monday) {
+			t.Errorf("Except.Next(%v) = %v, want %v", friday, got, monday)
+		}
+	}
+}
+
+func TestMilliseconds(t *testing.T) {
+	s := MustParse("0/250 * * * * * ?", WithMilliseconds())
+	now := time.Date(2026, 1, 1, 0, 0, 0, 600*int(time.Millisecond), time.Local)
+	want := []time.Time{
+		time.Date(2026, 1, 1, 0, 0, 0, 750*int(time.Millisecond), time.Local),
+		time.Date(2026, 1, 1, 0, 0, 1, 0, time.Local),
+		time.Date(2026, 1, 1, 0, 0, 1, 250*int(time.Millisecond), time.Local),
+	}
+	for _, w := range want {
+		if now = s.Next(now); !now.Equal(w) {
+			t.Fatalf("next = %v, want %v", now, w)
+		}
+	}
+	s = MustParse("500 0 0 * * * ?", WithMilliseconds())
+	if got, want := s.Next(time.Date(2026, 1, 1, 0, 0, 0, 600*int(time.Millisecond), time.Local)), time.Date(2026, 1, 1, 1, 0, 0, 500*int(time.Millisecond), time.Local); !got.Equal(want) {
+		t.Errorf("next = %v, want %v", got, want)
+	}
+	if _, err := Parse("1000 * * * * * ?", WithMilliseconds()); err == nil {
+		t.Error("millisecond 1000 should be rejected")
+	}
+}
+
+func TestEvery(t *testing.T) {
+	s, err := Every(250 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 1, 10, 0, 0, 100*int(time.Millisecond), time.Local)
+	if got, want := s.Next(now), time.Date(2026, 1, 1, 10, 0, 0, 250*int(time.Millisecond), time.Local); !got.Equal(want) {
+		t.Errorf("next = %v, want %v", got, want)
+	}
+	s, _ = Every(7 * time.Hour)
+	if got, want := s.Next(time.Date(2026, 1, 1, 22, 0, 0, 0, time.Local)), time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("next = %v, want %v", got, want)
+	}
+}
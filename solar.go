@@ -0,0 +1,106 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// SolarEvent is a daily event defined by the position of the sun.
+type SolarEvent int
+
+const (
+	// Sunrise is when the upper limb of the sun appears on the horizon.
+	Sunrise SolarEvent = iota
+	// Sunset is when the upper limb of the sun disappears below the horizon.
+	Sunset
+	// CivilDawn is the start of civil twilight, the sun 6° below the horizon in the morning.
+	CivilDawn
+	// CivilDusk is the end of civil twilight, the sun 6° below the horizon in the evening.
+	CivilDusk
+)
+
+// maxSolarDays 极昼极夜时最多往后找的天数
+const maxSolarDays = 400
+
+// j2000 儒略日2451545.0所在的日期
+var j2000 = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// solarSchedule fires at a solar event of every day at a location, computed locally with
+// the sunrise equation. The result is accurate to about a minute.
+type solarSchedule struct {
+	event     SolarEvent
+	latitude  float64
+	longitude float64
+	offset    time.Duration
+	loc       *time.Location
+}
+
+// Solar returns a schedule firing at event plus offset every day at the given latitude and
+// longitude (degrees, north and east positive). Days are taken in loc, time.Local if nil.
+// Days on which the event does not happen, e.g. polar night, are skipped.
+//
+//	Solar(Sunset, 31.23, 121.47, -30*time.Minute, nil) 每天日落前30分钟
+func Solar(event SolarEvent, latitude, longitude float64, offset time.Duration, loc *time.Location) (Schedule, error) {
+	if event < Sunrise || event > CivilDusk {
+		return nil, errors.New(fmt.Sprintf("solar event %d is not supported", event))
+	}
+	if latitude < -90 || latitude > 90 {
+		return nil, errors.New(fmt.Sprintf("latitude %f should be in [-90,90]", latitude))
+	}
+	if longitude < -180 || longitude > 180 {
+		return nil, errors.New(fmt.Sprintf("longitude %f should be in [-180,180]", longitude))
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return &solarSchedule{event: event, latitude: latitude, longitude: longitude, offset: offset, loc: loc}, nil
+}
+
+func (s *solarSchedule) Next(t time.Time) time.Time {
+	day := midnight(t.In(s.loc))
+	//offset可能跨天，从前一天开始算
+	for i := -1; i <= maxSolarDays; i++ {
+		at, ok := s.at(day.AddDate(0, 0, i))
+		if !ok {
+			continue
+		}
+		if next := at.Add(s.offset).Round(time.Second).In(s.loc); next.After(t) {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// at calculates the event on the date of day, ok is false if the sun does not reach the altitude that day
+func (s *solarSchedule) at(day time.Time) (at time.Time, ok bool) {
+	const rad = math.Pi / 180
+	n := float64(civilDays(j2000, day))
+	//平太阳时
+	j := n + 0.0008 - s.longitude/360
+	//太阳平近点角
+	m := math.Mod(357.5291+0.98560028*j, 360)
+	//中心差
+	c := 1.9148*math.Sin(m*rad) + 0.02*math.Sin(2*m*rad) + 0.0003*math.Sin(3*m*rad)
+	//黄经
+	lambda := math.Mod(m+c+180+102.9372, 360)
+	transit := 2451545.0 + j + 0.0053*math.Sin(m*rad) - 0.0069*math.Sin(2*lambda*rad)
+	//赤纬
+	sinDelta := math.Sin(lambda*rad) * math.Sin(23.4397*rad)
+	cosDelta := math.Cos(math.Asin(sinDelta))
+	altitude := -0.833
+	if s.event == CivilDawn || s.event == CivilDusk {
+		altitude = -6
+	}
+	cosOmega := (math.Sin(altitude*rad) - math.Sin(s.latitude*rad)*sinDelta) / (math.Cos(s.latitude*rad) * cosDelta)
+	if cosOmega < -1 || cosOmega > 1 {
+		return time.Time{}, false
+	}
+	omega := math.Acos(cosOmega) / rad
+	julian := transit + omega/360
+	if s.event == Sunrise || s.event == CivilDawn {
+		julian = transit - omega/360
+	}
+	return time.Unix(0, int64((julian-2440587.5)*86400*float64(time.Second))), true
+}
@@ -0,0 +1,48 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSolar(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err)
+	}
+	cases := []struct {
+		event    SolarEvent
+		lat, lng float64
+		offset   time.Duration
+		loc      *time.Location
+		from     time.Time
+		want     time.Time
+	}{
+		{Sunrise, 39.9042, 116.4074, 0, shanghai, time.Date(2026, 6, 21, 0, 0, 0, 0, shanghai), time.Date(2026, 6, 21, 4, 46, 0, 0, shanghai)},
+		{Sunset, 39.9042, 116.4074, -30 * time.Minute, shanghai, time.Date(2026, 6, 21, 0, 0, 0, 0, shanghai), time.Date(2026, 6, 21, 19, 16, 0, 0, shanghai)},
+		{Sunrise, 51.5074, -0.1278, 0, london, time.Date(2026, 12, 21, 12, 0, 0, 0, london), time.Date(2026, 12, 22, 8, 4, 0, 0, london)},
+		{CivilDusk, 51.5074, -0.1278, 0, london, time.Date(2026, 12, 21, 0, 0, 0, 0, london), time.Date(2026, 12, 21, 16, 34, 0, 0, london)},
+	}
+	for _, c := range cases {
+		s, err := Solar(c.event, c.lat, c.lng, c.offset, c.loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := s.Next(c.from)
+		if d := got.Sub(c.want); d < -3*time.Minute || d > 3*time.Minute {
+			t.Errorf("event %d at %f,%f: Next(%v) = %v, want about %v", c.event, c.lat, c.lng, c.from, got, c.want)
+		}
+	}
+	// 极夜跳过
+	s, err := Solar(Sunrise, 78.22, 15.65, 0, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)); got.Month() != time.February {
+		t.Errorf("first sunrise after polar night = %v, want February", got)
+	}
+}
@@ -0,0 +1,123 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// WithHashKey sets the key H values are derived from, e.g. the job or service name. Jobs with
+// different keys get different but stable values for the same expression. Expressions using H
+// require a key, given by WithHashKey or by the job's WithName, otherwise every process would
+// hash the same expression to the same time.
+func WithHashKey(key string) ParseOption {
+	return func(t *trigger) {
+		t.hashKey = key
+	}
+}
+
+// spreadField replaces the H and ~ items of a comma separated field with concrete values:
+//
+//	H        hash of the key in the whole range of the field (1-28 for the day, as for ~)
+//	H(2-5)   hash of the key in [2,5]
+//	H/15     hash of the key in [0,14] as the start of the increment, i.e. 7/15
+//	H(0-29)/10 hashed start in [0,9], every 10 up to 29
+//	~        random value in the whole range of the field, chosen at parse time
+//	0~30     random value in [0,30]
+func (t *trigger) spreadField(name, s string) (string, error) {
+	if !strings.ContainsAny(s, "H~") {
+		return s, nil
+	}
+	items := strings.Split(s, ",")
+	for i, item := range items {
+		var err error
+		if strings.HasPrefix(item, "H") {
+			items[i], err = t.hashItem(name, i, item)
+		} else if index := strings.IndexByte(item, '~'); index > -1 {
+			items[i], err = randomItem(name, item, index)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(items, ","), nil
+}
+
+func (t *trigger) hashItem(name string, index int, item string) (string, error) {
+	min, max := ranges[name][0], ranges[name][1]
+	if name == dayField {
+		//保证每个月都有这一天
+		max = 28
+	}
+	rest := item[1:]
+	if strings.HasPrefix(rest, "(") {
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return "", errors.New(fmt.Sprintf(name+" %s should be H(start-end)", item))
+		}
+		arr := strings.Split(rest[1:end], "-")
+		if len(arr) != 2 {
+			return "", errors.New(fmt.Sprintf(name+" %s should be H(start-end)", item))
+		}
+		start, err0 := strconv.ParseUint(arr[0], 10, 16)
+		end0, err1 := strconv.ParseUint(arr[1], 10, 16)
+		if err0 != nil || err1 != nil || uint(start) < min || uint(end0) > max || start > end0 {
+			return "", errors.New(fmt.Sprintf(name+" %s range should be in [%d,%d]", item, min, max))
+		}
+		min, max = uint(start), uint(end0)
+		rest = rest[end+1:]
+	}
+	if t.hashKey == "" {
+		return "", errors.New(fmt.Sprintf(name+" %s needs a hash key, use WithHashKey or WithName", item))
+	}
+	h := fnv.New32a()
+	h.Write([]byte(fmt.Sprintf("%s\x00%s\x00%d", t.hashKey, name, index)))
+	hash := uint(h.Sum32())
+	if rest == "" {
+		return strconv.Itoa(int(min + hash%(max-min+1))), nil
+	}
+	if !strings.HasPrefix(rest, "/") {
+		return "", errors.New(fmt.Sprintf(name+" %s should be H, H(start-end) or H/increment", item))
+	}
+	increment, err := strconv.ParseUint(rest[1:], 10, 16)
+	if err != nil || increment == 0 || uint(increment) > max-min+1 {
+		return "", errors.New(fmt.Sprintf(name+" %s increment should be in [1,%d]", item, max-min+1))
+	}
+	//起始值在第一个间隔内
+	start := min + hash%uint(increment)
+	values := make([]string, 0, (max-start)/uint(increment)+1)
+	for v := start; v <= max; v += uint(increment) {
+		values = append(values, strconv.Itoa(int(v)))
+	}
+	return strings.Join(values, ","), nil
+}
+
+func randomItem(name, item string, index int) (string, error) {
+	min, upper := ranges[name][0], ranges[name][1]
+	if name == dayField {
+		//和H一样，保证每个月都有这一天
+		upper = 28
+	}
+	max := upper
+	if left := item[:index]; left != "" {
+		v, err := strconv.ParseUint(left, 10, 16)
+		if err != nil {
+			return "", errors.New(fmt.Sprintf(name+" %s start:%s is not a positive integer", item, left))
+		}
+		min = uint(v)
+	}
+	if right := item[index+1:]; right != "" {
+		v, err := strconv.ParseUint(right, 10, 16)
+		if err != nil {
+			return "", errors.New(fmt.Sprintf(name+" %s end:%s is not a positive integer", item, right))
+		}
+		max = uint(v)
+	}
+	if min < ranges[name][0] || max > upper || min > max {
+		return "", errors.New(fmt.Sprintf(name+" %s range should be in [%d,%d]", item, ranges[name][0], upper))
+	}
+	return strconv.Itoa(int(min) + rand.Intn(int(max-min+1))), nil
+}
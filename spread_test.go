@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHashSpread(t *testing.T) {
+	a := MustParse("H H(2-5) * * * ?", WithHashKey("service-a")).(*trigger)
+	again := MustParse("H H(2-5) * * * ?", WithHashKey("service-a")).(*trigger)
+	if a.sec.start != again.sec.start || a.min.start != again.min.start {
+		t.Error("H should be stable for the same key")
+	}
+	if a.min.start < 2 || a.min.start > 5 {
+		t.Errorf("H(2-5) = %d, want in [2,5]", a.min.start)
+	}
+	seconds := make(map[uint]bool)
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		seconds[MustParse("H 0 * * * ?", WithHashKey(key)).(*trigger).sec.start] = true
+	}
+	if len(seconds) < 2 {
+		t.Error("H should spread different keys")
+	}
+	step := MustParse("0 H/15 * * * ?", WithHashKey("service-a")).(*trigger)
+	if len(step.min.values) != 4 || step.min.values[0] > 14 || step.min.values[1]-step.min.values[0] != 15 {
+		t.Errorf("H/15 = %v", step.min.values)
+	}
+	if day := MustParse("0 0 0 H * ?", WithHashKey("x")).(*trigger).day.start; day < 1 || day > 28 {
+		t.Errorf("day H = %d, want in [1,28]", day)
+	}
+	s := New()
+	id, err := s.AddJob("0 H * * * ?", func() {}, WithName("service-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.jobMap[id].s.(*trigger).min.start, MustParse("0 H * * * ?", WithHashKey("service-a")).(*trigger).min.start; got != want {
+		t.Errorf("job H = %d, want the value for its name %d", got, want)
+	}
+}
+
+func TestRandomSpread(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		tr := MustParse("0 0~30 ~ ~ * ?").(*trigger)
+		if tr.min.start > 30 || tr.hour.start > 23 || tr.day.start < 1 || tr.day.start > 28 {
+			t.Fatalf("0~30 = %d, ~ = %d, day ~ = %d", tr.min.start, tr.hour.start, tr.day.start)
+		}
+		next := tr.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local))
+		if next.Minute() != int(tr.min.start) {
+			t.Fatalf("next = %v", next)
+		}
+	}
+	for _, cron := range []string{"0 30~10 * * * ?", "0 0~60 * * * ?", "0 H(5-70) * * * ?", "0 H/0 * * * ?", "0 H(1-5 * * * ?", "0 0 0 20~31 * ?"} {
+		if _, err := Parse(cron, WithHashKey("k")); err == nil {
+			t.Errorf("%s should be rejected", cron)
+		}
+	}
+	//没有key时所有进程会哈希到同一时间
+	if _, err := Parse("0 H * * * ?"); err == nil {
+		t.Error("H without a hash key should be rejected")
+	}
+}
@@ -0,0 +1,314 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	systemdMinYear = 1970
+	systemdMaxYear = 2199
+)
+
+// systemdShorthands systemd预定义的日历事件
+var systemdShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+var systemdWeekdayAlias = map[string]int{
+	"sun": 0, "sunday": 0,
+	"mon": 1, "monday": 1,
+	"tue": 2, "tuesday": 2,
+	"wed": 3, "wednesday": 3,
+	"thu": 4, "thursday": 4,
+	"fri": 5, "friday": 5,
+	"sat": 6, "saturday": 6,
+}
+
+// systemdSchedule fires at the times of a systemd calendar event
+type systemdSchedule struct {
+	years    []bool
+	months   []bool
+	days     []bool
+	lastDays []bool
+	weekdays []bool
+	hours    []bool
+	mins     []bool
+	secs     []bool
+	loc      *time.Location
+}
+
+// ParseOnCalendar parses a systemd calendar event, the OnCalendar= setting of a systemd timer,
+// into a Schedule. The format is "[weekdays] [[year-]month-day] [hour:minute[:second]] [timezone]",
+// each component being *, a value, a list a,b, a range a..b or a repetition a/n or a..b/n.
+// month~day counts days from the end of the month. The shorthands minutely, hourly, daily,
+// monthly, weekly, yearly, annually, quarterly and semiannually are supported. Without a
+// timezone times are local. Fractional seconds are not supported.
+//
+//	ParseOnCalendar("Mon..Fri *-*-* 09:00:00")
+//	ParseOnCalendar("Sat *-*-1..7 18:00")  每月第一个星期六
+//	ParseOnCalendar("*-*~01 23:00")        每月最后一天
+func ParseOnCalendar(spec string) (Schedule, error) {
+	s := &systemdSchedule{loc: time.Local}
+	fields := strings.Fields(spec)
+	if len(fields) > 0 {
+		//daily UTC
+		if shorthand, ok := systemdShorthands[strings.ToLower(fields[0])]; ok {
+			fields = append(strings.Fields(shorthand), fields[1:]...)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("calendar event is empty")
+	}
+	var date, clock string
+	for i, f := range fields {
+		if i == 0 && s.weekdays == nil {
+			if weekdays, err := parseSystemdWeekdays(f); err == nil {
+				s.weekdays = weekdays
+				continue
+			}
+		}
+		if i == len(fields)-1 && i > 0 && f[0] != '*' && (f[0] < '0' || f[0] > '9') {
+			loc, err := time.LoadLocation(f)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("calendar event %s timezone %s: %v", spec, f, err))
+			}
+			s.loc = loc
+			continue
+		}
+		switch {
+		case strings.IndexByte(f, ':') > -1 && clock == "":
+			clock = f
+		case strings.ContainsAny(f, "-~") && date == "" && clock == "":
+			date = f
+		default:
+			return nil, errors.New(fmt.Sprintf("calendar event %s: unexpected %s", spec, f))
+		}
+	}
+	if err := s.parseDate(date); err != nil {
+		return nil, err
+	}
+	if err := s.parseClock(clock); err != nil {
+		return nil, err
+	}
+	if s.weekdays == nil {
+		s.weekdays, _ = parseSystemdComponent("*", 0, 6)
+	}
+	return s, nil
+}
+
+func (s *systemdSchedule) parseDate(date string) (err error) {
+	year, month, day := "*", "*", "*"
+	last := false
+	if date != "" {
+		if index := strings.IndexByte(date, '~'); index > -1 {
+			last = true
+			day = date[index+1:]
+			date = date[:index] + "-"
+		} else {
+			index = strings.LastIndexByte(date, '-')
+			day = date[index+1:]
+			date = date[:index+1]
+		}
+		//剩下的是year-month-或month-
+		arr := strings.Split(strings.TrimSuffix(date, "-"), "-")
+		switch len(arr) {
+		case 1:
+			month = arr[0]
+		case 2:
+			year, month = arr[0], arr[1]
+		default:
+			return errors.New(fmt.Sprintf("calendar date %s should be [year-]month-day", date))
+		}
+	}
+	if s.years, err = parseSystemdComponent(year, systemdMinYear, systemdMaxYear); err != nil {
+		return err
+	}
+	if s.months, err = parseSystemdComponent(month, 1, 12); err != nil {
+		return err
+	}
+	days, err := parseSystemdItems(day, 1, 31, last)
+	if err != nil {
+		return err
+	}
+	if last {
+		s.lastDays = days
+	} else {
+		s.days = days
+	}
+	return nil
+}
+
+func (s *systemdSchedule) parseClock(clock string) (err error) {
+	if clock == "" {
+		clock = "00:00:00"
+	}
+	arr := strings.Split(clock, ":")
+	if len(arr) == 2 {
+		arr = append(arr, "00")
+	}
+	if len(arr) != 3 {
+		return errors.New(fmt.Sprintf("calendar time %s should be hour:minute[:second]", clock))
+	}
+	if strings.IndexByte(arr[2], '.') > -1 {
+		return errors.New(fmt.Sprintf("calendar time %s: fractional seconds are not supported", clock))
+	}
+	if s.hours, err = parseSystemdComponent(arr[0], 0, 23); err != nil {
+		return err
+	}
+	if s.mins, err = parseSystemdComponent(arr[1], 0, 59); err != nil {
+		return err
+	}
+	s.secs, err = parseSystemdComponent(arr[2], 0, 59)
+	return err
+}
+
+// parseSystemdComponent parses *, a, a..b, a/n and a..b/n items separated by commas into
+// a set indexed by value
+func parseSystemdComponent(str string, min, max int) ([]bool, error) {
+	return parseSystemdItems(str, min, max, false)
+}
+
+// parseSystemdItems is parseSystemdComponent, with a/n counting down to min if reverse is true.
+// Days after ~ are counted from the end of the month, so ~07/1 is the last 7 days.
+func parseSystemdItems(str string, min, max int, reverse bool) ([]bool, error) {
+	set := make([]bool, max+1)
+	for _, item := range strings.Split(str, ",") {
+		start, end, step := min, max, 1
+		repeat := false
+		if index := strings.IndexByte(item, '/'); index > -1 {
+			n, err := strconv.Atoi(item[index+1:])
+			if err != nil || n < 1 {
+				return nil, errors.New(fmt.Sprintf("calendar %s repetition should be a positive integer", item))
+			}
+			step, repeat = n, true
+			item = item[:index]
+		}
+		if item != "*" {
+			var err error
+			bounds := strings.Split(item, "..")
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, errors.New(fmt.Sprintf("calendar %s is not a number", item))
+			}
+			switch {
+			case len(bounds) == 2:
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, errors.New(fmt.Sprintf("calendar %s is not a number", item))
+				}
+			case len(bounds) > 2:
+				return nil, errors.New(fmt.Sprintf("calendar %s is not a range", item))
+			case !repeat:
+				end = start
+			}
+		}
+		if repeat && reverse && item != "*" && strings.Index(item, "..") < 0 {
+			if start < min || start > max {
+				return nil, errors.New(fmt.Sprintf("calendar %s should be in [%d,%d]", item, min, max))
+			}
+			for v := start; v >= min; v -= step {
+				set[v] = true
+			}
+			continue
+		}
+		if start < min || end > max || start > end {
+			return nil, errors.New(fmt.Sprintf("calendar %s should be in [%d,%d]", item, min, max))
+		}
+		for v := start; v <= end; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// parseSystemdWeekdays parses weekday names, e.g. Mon..Fri or Sat,Sun
+func parseSystemdWeekdays(str string) ([]bool, error) {
+	set := make([]bool, 7)
+	for _, item := range strings.Split(str, ",") {
+		bounds := strings.Split(item, "..")
+		if len(bounds) > 2 {
+			return nil, errors.New(fmt.Sprintf("calendar weekday %s is not a range", item))
+		}
+		start, ok := systemdWeekdayAlias[strings.ToLower(bounds[0])]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("calendar weekday %s is unknown", bounds[0]))
+		}
+		end := start
+		if len(bounds) == 2 {
+			if end, ok = systemdWeekdayAlias[strings.ToLower(bounds[1])]; !ok {
+				return nil, errors.New(fmt.Sprintf("calendar weekday %s is unknown", bounds[1]))
+			}
+		}
+		//Sat..Mon跨过周日
+		for wd := start; ; wd = (wd + 1) % 7 {
+			set[wd] = true
+			if wd == end {
+				break
+			}
+		}
+	}
+	return set, nil
+}
+
+func (s *systemdSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc)
+	day := midnight(t)
+	for i := 0; i <= maxYears*366 && day.Year() <= systemdMaxYear; i++ {
+		if s.matchDate(day) {
+			if next, ok := s.nextClock(day, t); ok {
+				return next
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+func (s *systemdSchedule) matchDate(day time.Time) bool {
+	year, month, d := day.Date()
+	if year < systemdMinYear || !s.years[year] || !s.months[month] || !s.weekdays[day.Weekday()] {
+		return false
+	}
+	if s.lastDays != nil {
+		//~1是最后一天
+		return s.lastDays[getYearMonthDays(year, int(month))-d+1]
+	}
+	return s.days[d]
+}
+
+// nextClock returns the first time of the day after t
+func (s *systemdSchedule) nextClock(day, t time.Time) (time.Time, bool) {
+	year, month, d := day.Date()
+	for h := 0; h < 24; h++ {
+		if !s.hours[h] {
+			continue
+		}
+		if end := time.Date(year, month, d, h, 59, 59, 0, s.loc); !end.After(t) {
+			continue
+		}
+		for m := 0; m < 60; m++ {
+			if !s.mins[m] {
+				continue
+			}
+			for sec := 0; sec < 60; sec++ {
+				if !s.secs[sec] {
+					continue
+				}
+				if next := time.Date(year, month, d, h, m, sec, 0, s.loc); next.After(t) {
+					return next, true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
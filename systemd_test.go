@@ -0,0 +1,52 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOnCalendar(t *testing.T) {
+	from := time.Date(2026, 3, 14, 10, 0, 0, 0, time.Local)
+	local := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, time.Local)
+	}
+	cases := []struct {
+		spec string
+		want []time.Time
+	}{
+		{"Mon..Fri *-*-* 09:00:00", []time.Time{local(2026, 3, 16, 9, 0, 0), local(2026, 3, 17, 9, 0, 0)}},
+		{"*-*-01 00:00:00", []time.Time{local(2026, 4, 1, 0, 0, 0), local(2026, 5, 1, 0, 0, 0)}},
+		{"quarterly", []time.Time{local(2026, 4, 1, 0, 0, 0), local(2026, 7, 1, 0, 0, 0)}},
+		{"Sat *-*-1..7 18:00", []time.Time{local(2026, 4, 4, 18, 0, 0), local(2026, 5, 2, 18, 0, 0)}},
+		{"*-*~01 23:00", []time.Time{local(2026, 3, 31, 23, 0, 0), local(2026, 4, 30, 23, 0, 0)}},
+		{"Mon *-05~07/1 12:00", []time.Time{local(2026, 5, 25, 12, 0, 0), local(2027, 5, 31, 12, 0, 0)}},
+		{"Sat,Sun 10:00/15", []time.Time{local(2026, 3, 14, 10, 15, 0), local(2026, 3, 14, 10, 30, 0)}},
+		{"2027-02-28 08:30", []time.Time{local(2027, 2, 28, 8, 30, 0)}},
+		{"*-*-* *:0/20:30", []time.Time{local(2026, 3, 14, 10, 0, 30), local(2026, 3, 14, 10, 20, 30)}},
+		{"daily UTC", []time.Time{time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)}},
+	}
+	for _, c := range cases {
+		s, err := ParseOnCalendar(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		next := from
+		for _, want := range c.want {
+			next = s.Next(next)
+			if !next.Equal(want) {
+				t.Errorf("%s: next = %s, want %s", c.spec, next, want)
+				break
+			}
+		}
+	}
+	if s, _ := ParseOnCalendar("2025-01-01"); s != nil && !s.Next(from).IsZero() {
+		t.Errorf("past date should never fire")
+	}
+
+	for _, spec := range []string{"", "Mon..Fri *-*-* 25:00", "*-13-01", "Someday 10:00", "*-*-* 10:00:00.5", "*-*-* 10:00 Mars/Base"} {
+		if _, err := ParseOnCalendar(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package cron
+
+import (
+	"time"
+)
+
+// WithTimeout limits how long an execution of the job may run, overriding the scheduler's
+// default. When it has run for d its context is cancelled, it is recorded as timed out and a
+// JobTimedOut event is emitted. The scheduler stops waiting for it, so a job ignoring its
+// context holds up Stop, Remove or ReplaceRunning at most until its timeout. d <= 0 means
+// the default.
+func WithTimeout(d time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = d
+	}
+}
+
+// WithDefaultTimeout sets the timeout of the jobs without WithTimeout. d <= 0 means no timeout, the default.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(c *Scheduler) {
+		c.defaultTimeout = d
+	}
+}
+
+// timeoutOf returns the timeout of j, 0 if there is none
+func (c *Scheduler) timeoutOf(j *job) time.Duration {
+	if j.timeout > 0 {
+		return j.timeout
+	}
+	if c.defaultTimeout > 0 {
+		return c.defaultTimeout
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	rec := newRecorder()
+	s := New(WithClock(clock), WithDefaultTimeout(20*time.Second), WithEventHandler(rec.handle))
+	at := MustParse("0 1 8 * * ?")
+	hang := make(chan struct{})
+	defer close(hang)
+	hung := s.AddSchedule(at, func() { <-hang })
+	cancelled := make(chan struct{})
+	polite := s.AddScheduleJob(at, FuncJob(func(ctx context.Context) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}), WithTimeout(5*time.Second))
+	s.Start()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	rec.wait(t, JobStarted)
+	rec.wait(t, JobStarted)
+
+	//任务自己的超时优先于默认超时
+	clock.Advance(5 * time.Second)
+	if e := rec.wait(t, JobTimedOut); e.JobID != polite || e.Duration != 5*time.Second {
+		t.Errorf("first timed out event %+v, want job %d after 5s", e, polite)
+	}
+	<-cancelled
+	clock.Advance(15 * time.Second)
+	if e := rec.wait(t, JobTimedOut); e.JobID != hung || e.Duration != 20*time.Second {
+		t.Errorf("second timed out event %+v, want job %d after 20s", e, hung)
+	}
+	select {
+	case <-s.Stop().Done():
+	case <-time.After(time.Second):
+		t.Fatal("a hung job blocked Stop")
+	}
+	for _, id := range []uint{hung, polite} {
+		e := s.History(id)[0]
+		if !e.TimedOut || e.Err != context.DeadlineExceeded {
+			t.Errorf("job %d: execution %+v, want timed out", id, e)
+		}
+	}
+}
+
+func TestStopDuringTimeout(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	rec := newRecorder()
+	s := New(WithClock(clock), WithEventHandler(rec.handle))
+	hang := make(chan struct{})
+	defer close(hang)
+	hung := s.AddSchedule(MustParse("0 1 8 * * ?"), func() { <-hang }, WithTimeout(10*time.Second))
+	s.Start()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	rec.wait(t, JobStarted)
+
+	//不理会ctx的任务让Stop等到超时为止
+	done := s.Stop().Done()
+	clock.Advance(9 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("Stop did not wait for the job before its timeout")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("a job ignoring its context blocked Stop past its timeout")
+	}
+	if e := s.History(hung)[0]; !e.TimedOut || e.Err != context.DeadlineExceeded {
+		t.Errorf("execution %+v, want timed out", e)
+	}
+	rec.wait(t, JobTimedOut)
+}